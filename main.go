@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -15,6 +16,7 @@ var (
 	Version    string
 	jsonOutput = flag.Bool("json", false, "Output as JSON")
 	showVer    = flag.Bool("version", false, "Show version")
+	binaryTag  = flag.String("b", "", "Write the binary data of `TAG`, e.g. ThumbnailImage, to stdout")
 )
 
 func main() {
@@ -27,6 +29,17 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s photo.jpg\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -json photo.jpg\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s photo1.jpg photo2.jpg\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -b ThumbnailImage photo.jpg > thumb.jpg\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nCommands:\n")
+		fmt.Fprintf(os.Stderr, "  organize    Move files to paths computed from their metadata\n")
+	}
+
+	// Restore the interpreter from the cached snapshot instead of
+	// initializing Perl on every run
+	exiftool.EnableSnapshots(true)
+
+	if len(os.Args) > 1 && os.Args[1] == "organize" {
+		os.Exit(organize(os.Args[2:]))
 	}
 	flag.Parse()
 
@@ -55,6 +68,15 @@ func main() {
 	}
 	defer et.Close()
 
+	if *binaryTag != "" {
+		for _, filePath := range flag.Args() {
+			if err := et.ExtractBinaryTo(context.Background(), filePath, *binaryTag, os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", filePath, err)
+			}
+		}
+		return
+	}
+
 	// Store results for multiple files
 	var allResults []map[string]any
 
@@ -82,6 +104,56 @@ func main() {
 	}
 }
 
+// organize runs the organize command and returns the exit code.
+func organize(args []string) int {
+	fs := flag.NewFlagSet("organize", flag.ExitOnError)
+	dateFormat := fs.String("d", "", "Format date/time values with the strftime `FORMAT`")
+	dryRun := fs.Bool("dry-run", false, "Print the planned moves without moving any file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s organize [options] <template> <image_file> [image_file...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Move files to paths computed from their metadata. The template refers to\n")
+		fmt.Fprintf(os.Stderr, "tags as $TAG or ${GROUP:TAG} and may contain %%f (file name), %%e (extension)\n")
+		fmt.Fprintf(os.Stderr, "and %%c or %%-c (copy number for duplicate names).\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s organize -d %%Y/%%m/%%Y%%m%%d_%%H%%M%%S%%%%-c.%%%%e '$DateTimeOriginal' *.jpg\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s organize -dry-run '${Model}/%%f.%%e' *.jpg\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return 1
+	}
+
+	et, err := exiftool.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing ExifTool: %v\n", err)
+		return 1
+	}
+	defer et.Close()
+
+	opts := exiftool.OrganizeOptions{DateFormat: *dateFormat, DryRun: *dryRun}
+	moves, err := et.Organize(context.Background(), fs.Args()[1:], fs.Arg(0), opts)
+
+	code := 0
+	for _, move := range moves {
+		switch {
+		case move.Err != nil:
+			fmt.Fprintf(os.Stderr, "Error organizing %s: %v\n", move.Src, move.Err)
+			code = 1
+		case move.Dst != move.Src:
+			fmt.Printf("%s -> %s\n", move.Src, move.Dst)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		code = 1
+	}
+	return code
+}
+
 func printMetadata(filePath string, metadata map[string]any) {
 	if len(flag.Args()) > 1 {
 		fmt.Printf("======== %s\n", filePath)
@@ -95,11 +167,7 @@ func printMetadata(filePath string, metadata map[string]any) {
 	sort.Strings(keys)
 
 	for _, key := range keys {
-		value := metadata[key]
-		if str, ok := value.(string); ok && str == "[binary data]" {
-			continue // Skip binary data
-		}
-		fmt.Printf("%-32s : %v\n", key, value)
+		fmt.Printf("%-32s : %v\n", key, metadata[key])
 	}
 
 	if len(flag.Args()) > 1 {
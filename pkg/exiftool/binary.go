@@ -0,0 +1,86 @@
+package exiftool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ExtractBinary returns the data of a binary tag such as ThumbnailImage,
+// PreviewImage, JpgFromRaw or ICC_Profile, like the -b option. The tag may
+// include a group, e.g. "IFD1:ThumbnailImage". ReadMetadata only reports
+// the size of binary tags, as "(Binary data 1378 bytes)".
+// If the file has no such tag, the error wraps ErrTagNotFound.
+func (et *ExifTool) ExtractBinary(ctx context.Context, filePath string, tag string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := et.ExtractBinaryTo(ctx, filePath, tag, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ExtractBinaryTo writes the data of a binary tag to w. See ExtractBinary.
+func (et *ExifTool) ExtractBinaryTo(ctx context.Context, filePath string, tag string, w io.Writer) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	return et.extractBinary(ctx, f, tag, w)
+}
+
+// ExtractBinaryFromReader writes the data of a binary tag of the image data
+// provided by r to w. See ExtractBinary.
+func (et *ExifTool) ExtractBinaryFromReader(ctx context.Context, r io.Reader, tag string, w io.Writer) error {
+	return et.extractBinary(ctx, r, tag, w)
+}
+
+// extractBinary stages the image data in the sandbox and copies the data of
+// tag to w.
+func (et *ExifTool) extractBinary(ctx context.Context, r io.Reader, tag string, w io.Writer) error {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	tmpInput, guestInput := et.sandboxFile("input")
+	if err := stageFile(tmpInput, r); err != nil {
+		return err
+	}
+	defer os.Remove(tmpInput)
+
+	tmpOutput, guestOutput := et.sandboxFile("output")
+	defer os.Remove(tmpOutput)
+
+	var result struct {
+		Found    bool     `json:"found"`
+		Error    string   `json:"error"`
+		Warnings []string `json:"warnings"`
+	}
+	err := et.call(ctx, "extract_binary", map[string]any{
+		"file": guestInput,
+		"tag":  tag,
+		"dst":  guestOutput,
+	}, &result)
+	if err != nil {
+		return err
+	}
+	if err := et.exifToolError("read", result.Error, result.Warnings); err != nil {
+		return err
+	}
+	if !result.Found {
+		return fmt.Errorf("%w: %s", ErrTagNotFound, tag)
+	}
+
+	f, err := os.Open(tmpOutput)
+	if err != nil {
+		return fmt.Errorf("failed to read output file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("failed to copy output: %w", err)
+	}
+	return nil
+}
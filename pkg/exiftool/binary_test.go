@@ -0,0 +1,140 @@
+package exiftool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractBinary(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx := context.Background()
+	srcPath := filepath.Join("testdata", "test.jpg")
+
+	thumb, err := et.ExtractBinary(ctx, srcPath, "ThumbnailImage")
+	if err != nil {
+		t.Fatalf("ExtractBinary failed: %v", err)
+	}
+	if len(thumb) != 1378 {
+		t.Errorf("ThumbnailImage is %d bytes, want 1378", len(thumb))
+	}
+	if !bytes.HasPrefix(thumb, []byte{0xff, 0xd8}) {
+		t.Errorf("ThumbnailImage is not a JPEG: % x", thumb[:min(len(thumb), 4)])
+	}
+
+	var buf bytes.Buffer
+	if err := et.ExtractBinaryTo(ctx, srcPath, "ICC_Profile", &buf); err != nil {
+		t.Fatalf("ExtractBinaryTo failed: %v", err)
+	}
+	if profile := buf.Bytes(); len(profile) < 40 || string(profile[36:40]) != "acsp" {
+		t.Error("ICC_Profile has no ICC signature")
+	}
+
+	f, err := os.Open(srcPath)
+	if err != nil {
+		t.Fatalf("Failed to open test image: %v", err)
+	}
+	defer f.Close()
+	buf.Reset()
+	if err := et.ExtractBinaryFromReader(ctx, f, "ThumbnailImage", &buf); err != nil {
+		t.Fatalf("ExtractBinaryFromReader failed: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), thumb) {
+		t.Errorf("ExtractBinaryFromReader returned %d bytes, want the %d of ExtractBinary", buf.Len(), len(thumb))
+	}
+
+	metadata, err := et.ReadMetadata(srcPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	if got := metadata["ThumbnailImage"]; got != "(Binary data 1378 bytes)" {
+		t.Errorf("ThumbnailImage = %v, want size placeholder", got)
+	}
+}
+
+func TestExtractBinaryMissingTag(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	_, err = et.ExtractBinary(context.Background(), filepath.Join("testdata", "test.jpg"), "PreviewImage")
+	if !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("ExtractBinary error = %v, want ErrTagNotFound", err)
+	}
+}
+
+func TestWriteBinaryTags(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx := context.Background()
+	srcPath := filepath.Join("testdata", "test.jpg")
+
+	thumb, err := et.ExtractBinary(ctx, srcPath, "ThumbnailImage")
+	if err != nil {
+		t.Fatalf("ExtractBinary failed: %v", err)
+	}
+	profile, err := et.ExtractBinary(ctx, srcPath, "ICC_Profile")
+	if err != nil {
+		t.Fatalf("ExtractBinary failed: %v", err)
+	}
+
+	// A thumbnail with bytes that are neither valid UTF-8 nor NUL-free
+	newThumb := append(bytes.Clone(thumb[:len(thumb)-2]), 0x00, 0xfe, 0xff, 0xff, 0xd9)
+	packet := []byte(`<?xpacket begin='` + "\xef\xbb\xbf" + `' id='W5M0MpCehiHzreSzNTczkc9d'?>
+<x:xmpmeta xmlns:x='adobe:ns:meta/'>
+<rdf:RDF xmlns:rdf='http://www.w3.org/1999/02/22-rdf-syntax-ns#'>
+<rdf:Description rdf:about='' xmlns:dc='http://purl.org/dc/elements/1.1/'>
+<dc:title><rdf:Alt><rdf:li xml:lang='x-default'>Packet</rdf:li></rdf:Alt></dc:title>
+</rdf:Description>
+</rdf:RDF>
+</x:xmpmeta>
+<?xpacket end='w'?>`)
+
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+	result, err := et.WriteMetadataWithOptions(ctx, srcPath, dstPath, map[string]any{
+		"ThumbnailImage": newThumb,
+		"ICC_Profile":    bytes.NewReader(profile),
+		"XMP":            packet,
+	}, WriteOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("WriteMetadataWithOptions failed: %v (result %+v)", err, result)
+	}
+
+	got, err := et.ExtractBinary(ctx, dstPath, "ThumbnailImage")
+	if err != nil {
+		t.Fatalf("ExtractBinary failed: %v", err)
+	}
+	if !bytes.Equal(got, newThumb) {
+		t.Errorf("ThumbnailImage round trip mismatch: got %d bytes, want %d", len(got), len(newThumb))
+	}
+
+	got, err = et.ExtractBinary(ctx, dstPath, "ICC_Profile")
+	if err != nil {
+		t.Fatalf("ExtractBinary failed: %v", err)
+	}
+	if !bytes.Equal(got, profile) {
+		t.Errorf("ICC_Profile round trip mismatch: got %d bytes, want %d", len(got), len(profile))
+	}
+
+	metadata, err := et.ReadMetadata(dstPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	if metadata["Title"] != "Packet" {
+		t.Errorf("Title from XMP packet = %v, want Packet", metadata["Title"])
+	}
+}
@@ -0,0 +1,73 @@
+package exiftool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// CopyOptions configures CopyMetadata.
+type CopyOptions struct {
+	WriteOptions
+
+	// Tags lists the tags to copy. Names may include a group and wildcards,
+	// e.g. "EXIF:all" or "*Date", and redirect tags to another group or
+	// tag, e.g. "EXIF:all>XMP:all" or "DateTimeOriginal>XMP:DateCreated".
+	// Empty means all tags, written to their preferred groups.
+	Tags []string
+
+	// Exclude lists tags not to copy, like --TAG with -tagsFromFile.
+	Exclude []string
+}
+
+// tags returns the tags argument of SetNewValuesFromFile.
+func (o CopyOptions) tags() []string {
+	tags := append([]string(nil), o.Tags...)
+	if len(tags) == 0 && len(o.Exclude) > 0 {
+		tags = append(tags, "all")
+	}
+	for _, tag := range o.Exclude {
+		tags = append(tags, "-"+tag)
+	}
+	return tags
+}
+
+// CopyMetadata copies tags from the file at srcMetaPath into the image at
+// targetPath and writes the result to dstPath, like
+// "exiftool -tagsFromFile SRC TARGET". The image data of the target is
+// preserved. If dstPath is empty, the target file is modified in place.
+// The copied tags are reported in WriteResult.Set.
+func (et *ExifTool) CopyMetadata(ctx context.Context, srcMetaPath string, targetPath string, dstPath string, opts CopyOptions) (*WriteResult, error) {
+	meta, err := os.Open(srcMetaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata source file: %w", err)
+	}
+	defer meta.Close()
+
+	return writeFile(targetPath, dstPath, opts.WriteOptions, func(r io.Reader, w io.Writer) (*WriteResult, error) {
+		return et.copyMetadata(ctx, meta, r, w, opts)
+	})
+}
+
+// CopyMetadataTo copies tags from the file read from meta into the image
+// read from r and writes the modified image to w. Nothing is written to w
+// if the write fails.
+func (et *ExifTool) CopyMetadataTo(ctx context.Context, meta io.Reader, r io.Reader, w io.Writer, opts CopyOptions) (*WriteResult, error) {
+	return et.copyMetadata(ctx, meta, r, w, opts)
+}
+
+// copyMetadata copies tags from meta into the image read from r and copies
+// the rewritten image to w.
+func (et *ExifTool) copyMetadata(ctx context.Context, meta io.Reader, r io.Reader, w io.Writer, opts CopyOptions) (*WriteResult, error) {
+	return et.rewrite(ctx, r, w, "copy_metadata", opts.WriteOptions, func(enc *valueEncoder) (map[string]any, error) {
+		from, err := enc.file(meta)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"from": from,
+			"tags": opts.tags(),
+		}, nil
+	})
+}
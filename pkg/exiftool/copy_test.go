@@ -0,0 +1,84 @@
+package exiftool
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// strippedCopy returns a copy of test.jpg without metadata.
+func strippedCopy(t *testing.T, et *ExifTool) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stripped.jpg")
+	if _, err := et.DeleteTags(context.Background(), filepath.Join("testdata", "test.jpg"), path, "all"); err != nil {
+		t.Fatalf("DeleteTags failed: %v", err)
+	}
+	return path
+}
+
+func TestCopyMetadata(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx := context.Background()
+	target := strippedCopy(t, et)
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+	result, err := et.CopyMetadata(ctx, filepath.Join("testdata", "test.jpg"), target, dstPath, CopyOptions{
+		Exclude: []string{"Software"},
+	})
+	if err != nil {
+		t.Fatalf("CopyMetadata failed: %v", err)
+	}
+	if !result.Changed || !slices.Contains(result.Set, "Make") || slices.Contains(result.Set, "Software") {
+		t.Errorf("result = %+v, want Make copied and Software excluded", result)
+	}
+
+	metadata, err := et.ReadMetadata(dstPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	if metadata["Make"] != "Canon" || metadata["DateTimeOriginal"] != "2008:05:30 15:56:01" {
+		t.Errorf("Make = %v, DateTimeOriginal = %v, want copied values", metadata["Make"], metadata["DateTimeOriginal"])
+	}
+	if _, ok := metadata["Software"]; ok {
+		t.Error("Software should have been excluded")
+	}
+	if metadata["ImageWidth"] != float64(100) || metadata["ImageHeight"] != float64(68) {
+		t.Errorf("image size = %vx%v, image data should be preserved", metadata["ImageWidth"], metadata["ImageHeight"])
+	}
+}
+
+func TestCopyMetadataRedirect(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx := context.Background()
+	target := strippedCopy(t, et)
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+	_, err = et.CopyMetadata(ctx, filepath.Join("testdata", "test.jpg"), target, dstPath, CopyOptions{
+		Tags: []string{"EXIF:all>XMP:all"},
+	})
+	if err != nil {
+		t.Fatalf("CopyMetadata failed: %v", err)
+	}
+
+	metadata, err := et.ReadMetadataWithOptions(ctx, dstPath, ReadOptions{Groups: "0"})
+	if err != nil {
+		t.Fatalf("ReadMetadataWithOptions failed: %v", err)
+	}
+	if metadata["XMP:Make"] != "Canon" {
+		t.Errorf("XMP:Make = %v, want Canon", metadata["XMP:Make"])
+	}
+	if _, ok := metadata["EXIF:Make"]; ok {
+		t.Error("EXIF:Make should not have been written")
+	}
+}
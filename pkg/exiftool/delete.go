@@ -0,0 +1,50 @@
+package exiftool
+
+import (
+	"context"
+	"io"
+)
+
+// DeleteOptions configures DeleteTagsWithOptions.
+type DeleteOptions struct {
+	WriteOptions
+
+	// Keep lists tags to preserve although they match the deleted tags,
+	// e.g. "ICC_Profile" and "Orientation" when deleting "all". They are
+	// copied back from the source like "-all= -tagsFromFile @ -ICC_Profile".
+	Keep []string
+}
+
+// DeleteTags deletes tags from an image file, like -TAG= on the command
+// line. Tags may be names such as "SerialNumber", groups such as "GPS:all"
+// or "XMP:all", or "all" for all metadata that can be deleted safely. If
+// dstPath is empty, the source file is modified in place. The deleted tags
+// are reported in WriteResult.Set and unknown tags in WriteResult.Rejected.
+func (et *ExifTool) DeleteTags(ctx context.Context, srcPath string, dstPath string, tags ...string) (*WriteResult, error) {
+	return et.DeleteTagsWithOptions(ctx, srcPath, dstPath, tags, DeleteOptions{})
+}
+
+// DeleteTagsWithOptions deletes tags from an image file as configured by
+// opts. See DeleteTags.
+func (et *ExifTool) DeleteTagsWithOptions(ctx context.Context, srcPath string, dstPath string, tags []string, opts DeleteOptions) (*WriteResult, error) {
+	return writeFile(srcPath, dstPath, opts.WriteOptions, func(r io.Reader, w io.Writer) (*WriteResult, error) {
+		return et.deleteTags(ctx, r, w, tags, opts)
+	})
+}
+
+// DeleteTagsTo deletes tags from the image read from r and writes the
+// modified image to w. Nothing is written to w if the write fails.
+func (et *ExifTool) DeleteTagsTo(ctx context.Context, r io.Reader, w io.Writer, tags []string, opts DeleteOptions) (*WriteResult, error) {
+	return et.deleteTags(ctx, r, w, tags, opts)
+}
+
+// deleteTags deletes the tags from the image read from r and copies the
+// rewritten image to w.
+func (et *ExifTool) deleteTags(ctx context.Context, r io.Reader, w io.Writer, tags []string, opts DeleteOptions) (*WriteResult, error) {
+	return et.rewrite(ctx, r, w, "delete_tags", opts.WriteOptions, func(enc *valueEncoder) (map[string]any, error) {
+		return map[string]any{
+			"tags": tags,
+			"keep": opts.Keep,
+		}, nil
+	})
+}
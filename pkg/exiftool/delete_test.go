@@ -0,0 +1,99 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeleteTags(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+	result, err := et.DeleteTags(context.Background(), srcPath, dstPath, "GPS:all", "Software")
+	if err != nil {
+		t.Fatalf("DeleteTags failed: %v", err)
+	}
+	if !result.Changed || len(result.Set) != 2 {
+		t.Errorf("result = %+v, want 2 deleted tags and a change", result)
+	}
+
+	metadata, err := et.ReadMetadata(dstPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	for tag := range metadata {
+		if strings.HasPrefix(tag, "GPS") {
+			t.Errorf("GPS tag %s should have been deleted", tag)
+		}
+	}
+	if _, ok := metadata["Software"]; ok {
+		t.Error("Software should have been deleted")
+	}
+	if metadata["Make"] != "Canon" {
+		t.Errorf("Make = %v, other tags should be kept", metadata["Make"])
+	}
+}
+
+func TestDeleteAllKeepTags(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+	_, err = et.DeleteTagsWithOptions(context.Background(), srcPath, dstPath, []string{"all"}, DeleteOptions{
+		Keep: []string{"ICC_Profile", "Orientation"},
+	})
+	if err != nil {
+		t.Fatalf("DeleteTagsWithOptions failed: %v", err)
+	}
+
+	metadata, err := et.ReadMetadata(dstPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	for _, tag := range []string{"Make", "Model", "DateTimeOriginal", "ThumbnailImage"} {
+		if _, ok := metadata[tag]; ok {
+			t.Errorf("%s should have been deleted", tag)
+		}
+	}
+	if _, ok := metadata["Orientation"]; !ok {
+		t.Error("Orientation should have been kept")
+	}
+	if _, ok := metadata["ProfileDescription"]; !ok {
+		t.Error("ICC_Profile should have been kept")
+	}
+}
+
+func TestDeleteTagsRejected(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+	result, err := et.DeleteTagsWithOptions(context.Background(), srcPath, dstPath, []string{"NoSuchTag"}, DeleteOptions{
+		WriteOptions: WriteOptions{Strict: true},
+	})
+	if !errors.Is(err, ErrTagRejected) {
+		t.Fatalf("DeleteTagsWithOptions error = %v, want ErrTagRejected", err)
+	}
+	if result == nil || len(result.Rejected) != 1 || result.Rejected[0].Tag != "NoSuchTag" {
+		t.Errorf("result = %+v, want NoSuchTag rejected", result)
+	}
+}
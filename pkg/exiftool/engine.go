@@ -0,0 +1,152 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// ErrEngineInitialized is returned by SetCompilationCacheDir once the shared
+// wasm engine has been created.
+var ErrEngineInitialized = errors.New("exiftool: engine already initialized")
+
+// engine holds the wazero runtime and the compiled ExifTool module shared by
+// all instances in the process. Compiling the module is by far the most
+// expensive part of creating an instance, so it is done only once.
+type engine struct {
+	runtime   wazero.Runtime
+	compiled  wazero.CompiledModule
+	wasmBytes []byte
+	cacheDir  string
+
+	// memory image of an initialized instance, see EnableSnapshots
+	snapshotMu     sync.Mutex
+	snapshot       []byte
+	snapshotRead   bool // the cache directory has been checked
+	snapshotBroken bool // a restored instance failed, don't retry
+}
+
+var (
+	engineMu      sync.Mutex
+	sharedEngine  *engine
+	cacheDir      string
+	cacheDirIsSet bool
+)
+
+// SetCompilationCacheDir sets the directory where the compiled wasm module is
+// cached between processes. An empty dir disables the on-disk cache.
+// By default the cache lives in an "exiftool-go" directory below
+// os.UserCacheDir. It must be called before the first instance is created.
+func SetCompilationCacheDir(dir string) error {
+	engineMu.Lock()
+	defer engineMu.Unlock()
+
+	if sharedEngine != nil {
+		return ErrEngineInitialized
+	}
+	cacheDir = dir
+	cacheDirIsSet = true
+	return nil
+}
+
+// defaultCacheDir returns the default compilation cache directory, or an
+// empty string if the platform has no user cache directory.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "exiftool-go")
+}
+
+// loadEngine returns the shared engine, creating it on first use.
+func loadEngine() (*engine, error) {
+	engineMu.Lock()
+	defer engineMu.Unlock()
+
+	if sharedEngine != nil {
+		return sharedEngine, nil
+	}
+
+	dir := cacheDir
+	if !cacheDirIsSet {
+		dir = defaultCacheDir()
+	}
+
+	e, err := newEngine(context.Background(), dir)
+	if err != nil {
+		return nil, err
+	}
+	sharedEngine = e
+	return e, nil
+}
+
+// newEngine creates a runtime with the host modules required by zeroperl and
+// compiles the embedded wasm binary. If cacheDir is not empty, compiled code
+// is stored there and reused by later processes.
+func newEngine(ctx context.Context, cacheDir string) (*engine, error) {
+	// Load wasm binary
+	wasmBytes, err := wasmFS.ReadFile("wasm/exiftool.wasm")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wasm: %w", err)
+	}
+
+	// Closing modules when the context passed to a call is done is what lets
+	// per-call contexts interrupt a running evaluation
+	config := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if cacheDir != "" {
+		// The cache only speeds up startup, so carry on without it if the
+		// directory is unusable
+		if cache, err := wazero.NewCompilationCacheWithDir(cacheDir); err == nil {
+			config = config.WithCompilationCache(cache)
+		}
+	}
+
+	// Create wazero runtime
+	runtime := wazero.NewRuntimeWithConfig(ctx, config)
+
+	// Instantiate WASI snapshot preview1
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+
+	// Create env module for host function callback
+	_, err = runtime.NewHostModuleBuilder("env").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, funcId, argPtr, argLen uint32) uint32 {
+			return 0
+		}).
+		Export("call_host_function").
+		Instantiate(ctx)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to create env module: %w", err)
+	}
+
+	// Compile module
+	compiled, err := runtime.CompileModule(ctx, wasmBytes)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to compile module: %w", err)
+	}
+
+	return &engine{
+		runtime:   runtime,
+		compiled:  compiled,
+		wasmBytes: wasmBytes,
+		cacheDir:  cacheDir,
+	}, nil
+}
+
+// close releases the runtime and every module instantiated from it.
+func (e *engine) close(ctx context.Context) error {
+	return e.runtime.Close(ctx)
+}
@@ -0,0 +1,108 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestInstancesShareEngine(t *testing.T) {
+	et1, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et1.Close()
+
+	et2, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et2.Close()
+
+	if et1.runtime != et2.runtime {
+		t.Error("Instances should share the same runtime")
+	}
+
+	// Closing one instance must not affect the other
+	et1.Close()
+	if _, err := et2.Version(); err != nil {
+		t.Errorf("Version failed after closing another instance: %v", err)
+	}
+}
+
+func TestSetCompilationCacheDirAfterInit(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	if err := SetCompilationCacheDir(t.TempDir()); !errors.Is(err, ErrEngineInitialized) {
+		t.Errorf("Expected ErrEngineInitialized, got %v", err)
+	}
+}
+
+// BenchmarkNewCold measures instance creation including compilation of the
+// wasm module, as every New call did before the engine was shared.
+func BenchmarkNewCold(b *testing.B) {
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		eng, err := newEngine(ctx, "")
+		if err != nil {
+			b.Fatalf("Failed to create engine: %v", err)
+		}
+		et, err := newInstance(ctx, eng)
+		if err != nil {
+			b.Fatalf("Failed to create ExifTool: %v", err)
+		}
+		et.Close()
+		eng.close(ctx)
+	}
+}
+
+// BenchmarkNewDiskCache measures a fresh process that finds the compiled
+// module in the on-disk compilation cache.
+func BenchmarkNewDiskCache(b *testing.B) {
+	ctx := context.Background()
+	dir := b.TempDir()
+
+	// Populate the cache
+	eng, err := newEngine(ctx, dir)
+	if err != nil {
+		b.Fatalf("Failed to create engine: %v", err)
+	}
+	eng.close(ctx)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		eng, err := newEngine(ctx, dir)
+		if err != nil {
+			b.Fatalf("Failed to create engine: %v", err)
+		}
+		et, err := newInstance(ctx, eng)
+		if err != nil {
+			b.Fatalf("Failed to create ExifTool: %v", err)
+		}
+		et.Close()
+		eng.close(ctx)
+	}
+}
+
+// BenchmarkNewWarm measures New once the shared engine exists.
+func BenchmarkNewWarm(b *testing.B) {
+	// Make sure the engine is compiled before timing
+	et, err := New()
+	if err != nil {
+		b.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	et.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		et, err := New()
+		if err != nil {
+			b.Fatalf("Failed to create ExifTool: %v", err)
+		}
+		et.Close()
+	}
+}
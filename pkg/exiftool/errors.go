@@ -0,0 +1,65 @@
+package exiftool
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedFileType is matched by an ExifToolError when ExifTool
+	// does not recognize the file or can't write files of its type.
+	ErrUnsupportedFileType = errors.New("exiftool: unsupported file type")
+
+	// ErrFileFormat is matched by an ExifToolError when the file is empty,
+	// truncated or otherwise corrupt.
+	ErrFileFormat = errors.New("exiftool: file format error")
+)
+
+// PerlError is returned when the Perl code run for an operation died or
+// produced no usable response.
+type PerlError struct {
+	// Message is the Perl error message ($@).
+	Message string
+	// Stderr is everything the interpreter wrote to stderr during the call.
+	Stderr string
+}
+
+func (e *PerlError) Error() string {
+	return "exiftool: perl error: " + e.Message
+}
+
+// ExifToolError is returned when ExifTool reports an error for a file,
+// e.g. through the Error tag of ImageInfo or a failed WriteInfo.
+// Use errors.Is with ErrUnsupportedFileType or ErrFileFormat to check for
+// common causes.
+type ExifToolError struct {
+	// Op is the operation that failed, e.g. "read" or "write".
+	Op string
+	// Message is the error reported by ExifTool.
+	Message string
+	// Warnings are the warnings ExifTool reported along with the error.
+	Warnings []string
+	// Stderr is everything the interpreter wrote to stderr during the call.
+	Stderr string
+}
+
+func (e *ExifToolError) Error() string {
+	return "exiftool: " + e.Op + ": " + e.Message
+}
+
+// Is reports whether the ExifTool message matches one of the sentinel errors.
+func (e *ExifToolError) Is(target error) bool {
+	msg := strings.ToLower(e.Message)
+	switch target {
+	case ErrUnsupportedFileType:
+		return strings.HasPrefix(msg, "unknown file type") ||
+			strings.HasPrefix(msg, "unsupported file type") ||
+			strings.HasPrefix(msg, "can't currently write")
+	case ErrFileFormat:
+		return strings.Contains(msg, "format error") ||
+			strings.Contains(msg, "file is empty") ||
+			strings.Contains(msg, "corrupt") ||
+			strings.Contains(msg, "truncated")
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExifToolErrorIs(t *testing.T) {
+	tests := []struct {
+		message string
+		target  error
+		want    bool
+	}{
+		{"Unknown file type", ErrUnsupportedFileType, true},
+		{"Can't currently write RIFF WAV files", ErrUnsupportedFileType, true},
+		{"File format error", ErrFileFormat, true},
+		{"JPEG format error", ErrFileFormat, true},
+		{"File is empty", ErrFileFormat, true},
+		{"Unknown file type", ErrFileFormat, false},
+		{"Error opening file", ErrUnsupportedFileType, false},
+	}
+
+	for _, tt := range tests {
+		err := error(&ExifToolError{Op: "read", Message: tt.message})
+		if got := errors.Is(err, tt.target); got != tt.want {
+			t.Errorf("errors.Is(%q, %v) = %v, want %v", tt.message, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestReadMetadataUnsupportedFileType(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	data := []byte("\xde\xad\xbe\xef this is not an image \x00\x01\x02\x03")
+	_, err = et.ReadMetadataFromBytes(context.Background(), data)
+	if !errors.Is(err, ErrUnsupportedFileType) {
+		t.Fatalf("Expected ErrUnsupportedFileType, got %v", err)
+	}
+
+	var etErr *ExifToolError
+	if !errors.As(err, &etErr) {
+		t.Fatalf("Expected *ExifToolError, got %T", err)
+	}
+	if etErr.Op != "read" || etErr.Message == "" {
+		t.Errorf("Unexpected error details: %+v", etErr)
+	}
+}
+
+func TestWriteMetadataTruncatedFile(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "test.jpg"))
+	if err != nil {
+		t.Fatalf("Failed to read test image: %v", err)
+	}
+
+	var out strings.Builder
+	err = et.WriteMetadataTo(context.Background(), strings.NewReader(string(data[:len(data)/3])), &out, map[string]any{
+		"Artist": "Test",
+	})
+
+	var etErr *ExifToolError
+	if !errors.As(err, &etErr) {
+		t.Fatalf("Expected *ExifToolError, got %v", err)
+	}
+	if etErr.Op != "write" {
+		t.Errorf("Expected write operation, got %q", etErr.Op)
+	}
+	if out.Len() != 0 {
+		t.Error("Nothing should be written when ExifTool fails")
+	}
+}
+
+func TestPerlError(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	et.mu.Lock()
+	err = et.call(context.Background(), "no_such_routine", map[string]any{}, nil)
+	et.mu.Unlock()
+
+	var perlErr *PerlError
+	if !errors.As(err, &perlErr) {
+		t.Fatalf("Expected *PerlError, got %v", err)
+	}
+	if !strings.Contains(perlErr.Message, "unknown routine") {
+		t.Errorf("Unexpected Perl error message: %q", perlErr.Message)
+	}
+
+	// A Perl error must not break the instance
+	if _, err := et.Version(); err != nil {
+		t.Errorf("Version failed after Perl error: %v", err)
+	}
+}
+
+func TestNewPreloadError(t *testing.T) {
+	preload := perlPreload
+	defer func() { perlPreload = preload }()
+	perlPreload = "use Image::ExifTool;\ndie 'preload broken';\n"
+
+	et, err := New()
+	if err == nil {
+		et.Close()
+		t.Fatal("New succeeded, want error")
+	}
+	var perlErr *PerlError
+	if !errors.As(err, &perlErr) || !strings.Contains(perlErr.Message, "preload broken") {
+		t.Errorf("New error = %v, want *PerlError with the die message", err)
+	}
+}
@@ -9,13 +9,17 @@ import (
 	"embed"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
-	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 )
 
 //go:embed wasm/exiftool.wasm
@@ -28,16 +32,31 @@ const (
 	dataEnd   = 1024 * 1024 // 1MB
 )
 
+// ErrInstanceBroken is returned when the wasm module of an instance was
+// interrupted or trapped during a call. Such an instance can't be used
+// anymore and should be closed and replaced; a Pool does this automatically.
+var ErrInstanceBroken = errors.New("exiftool: instance is broken")
+
+// perlPreload is evaluated once when an instance is initialized. It loads
+// Image::ExifTool and defines the ExifToolGo package used by call.
+//
+//go:embed exiftoolgo.pl
+var perlPreload string
+
 // ExifTool represents an ExifTool instance backed by WebAssembly.
+// It is safe for concurrent use, but calls are serialized; use a Pool to
+// process files in parallel.
 type ExifTool struct {
 	mu      sync.Mutex
 	ctx     context.Context
-	runtime wazero.Runtime
+	runtime wazero.Runtime // shared by all instances, see engine
 	mod     api.Module
 	stdout  *bytes.Buffer
 	stderr  *bytes.Buffer
 	tmpDir  string
 	devDir  string
+	seq     uint64 // counter for per-call sandbox file names, guarded by mu
+	trapped bool   // set when the wasm module failed or was interrupted mid-call, guarded by mu
 
 	// cached functions
 	mallocFn    api.Function
@@ -56,13 +75,18 @@ func New() (*ExifTool, error) {
 }
 
 // NewWithContext creates a new ExifTool instance with the given context.
+// The embedded wasm module is compiled once per process and shared by all
+// instances; see SetCompilationCacheDir.
 func NewWithContext(ctx context.Context) (*ExifTool, error) {
-	// Load wasm binary
-	wasmBytes, err := wasmFS.ReadFile("wasm/exiftool.wasm")
+	eng, err := loadEngine()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read wasm: %w", err)
+		return nil, err
 	}
+	return newInstance(ctx, eng)
+}
 
+// newInstance instantiates the compiled module of eng and initializes Perl.
+func newInstance(ctx context.Context, eng *engine) (*ExifTool, error) {
 	// Create temp directory
 	tmpDir, err := os.MkdirTemp("", "exiftool-go-*")
 	if err != nil {
@@ -81,34 +105,18 @@ func NewWithContext(ctx context.Context) (*ExifTool, error) {
 	}
 
 	et := &ExifTool{
-		ctx:    ctx,
-		stdout: &bytes.Buffer{},
-		stderr: &bytes.Buffer{},
-		tmpDir: tmpDir,
-		devDir: devDir,
-	}
-
-	// Create wazero runtime
-	et.runtime = wazero.NewRuntime(ctx)
-
-	// Instantiate WASI snapshot preview1
-	wasi_snapshot_preview1.MustInstantiate(ctx, et.runtime)
-
-	// Create env module for host function callback
-	_, err = et.runtime.NewHostModuleBuilder("env").
-		NewFunctionBuilder().
-		WithFunc(func(ctx context.Context, m api.Module, funcId, argPtr, argLen uint32) uint32 {
-			return 0
-		}).
-		Export("call_host_function").
-		Instantiate(ctx)
-	if err != nil {
-		et.Close()
-		return nil, fmt.Errorf("failed to create env module: %w", err)
+		ctx:     ctx,
+		runtime: eng.runtime,
+		stdout:  &bytes.Buffer{},
+		stderr:  &bytes.Buffer{},
+		tmpDir:  tmpDir,
+		devDir:  devDir,
 	}
 
-	// Configure module with WASI settings
+	// Configure module with WASI settings. The module is anonymous so that
+	// any number of instances can share the runtime.
 	config := wazero.NewModuleConfig().
+		WithName("").
 		WithStdout(et.stdout).
 		WithStderr(et.stderr).
 		WithArgs("perl").
@@ -117,7 +125,7 @@ func NewWithContext(ctx context.Context) (*ExifTool, error) {
 			WithDirMount(devDir, "/dev"))
 
 	// Instantiate module
-	et.mod, err = et.runtime.InstantiateWithConfig(ctx, wasmBytes, config)
+	et.mod, err = eng.runtime.InstantiateModule(ctx, eng.compiled, config)
 	if err != nil {
 		et.Close()
 		return nil, fmt.Errorf("failed to instantiate module: %w", err)
@@ -143,6 +151,20 @@ func NewWithContext(ctx context.Context) (*ExifTool, error) {
 	et.startRewind = et.mod.ExportedFunction("asyncify_start_rewind")
 	et.stopRewind = et.mod.ExportedFunction("asyncify_stop_rewind")
 
+	// Restore the initialized interpreter from a snapshot if possible
+	if snapshotsEnabled.Load() {
+		if image := eng.loadSnapshot(); image != nil {
+			if err := et.restore(ctx, image); err == nil {
+				return et, nil
+			}
+			// The snapshot is unusable, fall back to a full initialization
+			// in a fresh module
+			eng.discardSnapshot()
+			et.Close()
+			return newInstance(ctx, eng)
+		}
+	}
+
 	// Call _initialize
 	if initFn := et.mod.ExportedFunction("_initialize"); initFn != nil {
 		if _, err := initFn.Call(ctx); err != nil {
@@ -153,75 +175,128 @@ func NewWithContext(ctx context.Context) (*ExifTool, error) {
 
 	// Call zeroperl_init to initialize Perl interpreter
 	if perlInitFn := et.mod.ExportedFunction("zeroperl_init"); perlInitFn != nil {
-		if _, err := et.callWithAsyncify(perlInitFn); err != nil {
+		if _, err := et.callWithAsyncify(ctx, perlInitFn); err != nil {
 			et.Close()
 			return nil, fmt.Errorf("zeroperl_init failed: %w", err)
 		}
 	}
 
+	// Load the ExifTool modules once instead of on every call
+	if err := et.preload(ctx); err != nil {
+		et.Close()
+		return nil, fmt.Errorf("failed to load ExifTool: %w", err)
+	}
+
+	if snapshotsEnabled.Load() {
+		eng.storeSnapshot(et.mod.Memory())
+	}
+
 	return et, nil
 }
 
+// preloadMarker is printed after perlPreload has run to the end.
+const preloadMarker = "exiftoolgo:loaded"
+
+// preload evaluates perlPreload. If it didn't run to the end, e.g. because
+// a module failed to load, the error is returned as *PerlError rather than
+// surfacing as an unrelated error on the first call.
+func (et *ExifTool) preload(ctx context.Context) error {
+	output, err := et.eval(ctx, perlPreload+"\nprint '"+preloadMarker+"';\n")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(output, preloadMarker) {
+		return nil
+	}
+
+	stderr := et.stderr.String()
+	message, err := et.eval(ctx, "print $@;")
+	if err != nil {
+		return err
+	}
+	message = strings.TrimSpace(message)
+	if message == "" {
+		message = "exiftoolgo.pl did not finish loading"
+	}
+	return &PerlError{Message: message, Stderr: stderr}
+}
+
 // Close releases all resources.
+// The shared runtime is left running for other instances.
 func (et *ExifTool) Close() error {
 	if et.mod != nil {
 		et.mod.Close(et.ctx)
 	}
-	if et.runtime != nil {
-		et.runtime.Close(et.ctx)
-	}
 	if et.tmpDir != "" {
 		os.RemoveAll(et.tmpDir)
 	}
 	return nil
 }
 
+// healthy reports whether the wasm module is still usable. A module that
+// trapped may have left the Perl interpreter in an inconsistent state.
+func (et *ExifTool) healthy() bool {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	return !et.trapped
+}
+
 // callWithAsyncify wraps a function call with asyncify support.
-func (et *ExifTool) callWithAsyncify(fn api.Function, args ...uint64) ([]uint64, error) {
+func (et *ExifTool) callWithAsyncify(ctx context.Context, fn api.Function, args ...uint64) ([]uint64, error) {
 	mem := et.mod.Memory()
 	dataBuffer := make([]byte, 8)
 
 	for {
-		results, err := fn.Call(et.ctx, args...)
+		results, err := fn.Call(ctx, args...)
 		if err != nil {
 			return nil, err
 		}
 
-		stateResults, _ := et.getState.Call(et.ctx)
+		stateResults, _ := et.getState.Call(ctx)
 		state := uint32(stateResults[0])
 
 		switch state {
 		case 0: // NORMAL
 			return results, nil
 		case 1: // UNWINDING
-			et.stopUnwind.Call(et.ctx)
+			et.stopUnwind.Call(ctx)
 			binary.LittleEndian.PutUint32(dataBuffer[0:4], dataStart)
 			binary.LittleEndian.PutUint32(dataBuffer[4:8], dataEnd)
 			mem.Write(dataAddr, dataBuffer)
-			et.startRewind.Call(et.ctx, dataAddr)
+			et.startRewind.Call(ctx, dataAddr)
 		case 2: // REWINDING
-			et.stopRewind.Call(et.ctx)
+			et.stopRewind.Call(ctx)
 			return results, nil
 		}
 	}
 }
 
 // eval executes Perl code and returns stdout.
-func (et *ExifTool) eval(code string) (string, error) {
-	et.mu.Lock()
-	defer et.mu.Unlock()
+// If ctx is done while the code runs, the module is closed and the instance
+// becomes unusable; the returned error wraps both ctx.Err() and
+// ErrInstanceBroken.
+// The caller must hold et.mu for the whole operation, including staging
+// input files and collecting output files.
+func (et *ExifTool) eval(ctx context.Context, code string) (string, error) {
+	if et.trapped {
+		return "", ErrInstanceBroken
+	}
+	// Nothing has run yet, so the instance stays usable
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 
 	et.stdout.Reset()
 	et.stderr.Reset()
 
 	// Write code to wasm memory
 	codeBytes := append([]byte(code), 0)
-	results, err := et.mallocFn.Call(et.ctx, uint64(len(codeBytes)))
+	results, err := et.mallocFn.Call(ctx, uint64(len(codeBytes)))
 	if err != nil {
-		return "", fmt.Errorf("malloc failed: %w", err)
+		return "", et.fail(ctx, "malloc", err)
 	}
 	codePtr := uint32(results[0])
-	defer et.freeFn.Call(et.ctx, uint64(codePtr))
+	defer et.freeFn.Call(ctx, uint64(codePtr))
 
 	mem := et.mod.Memory()
 	if !mem.Write(codePtr, codeBytes) {
@@ -229,14 +304,14 @@ func (et *ExifTool) eval(code string) (string, error) {
 	}
 
 	// Call eval
-	_, err = et.callWithAsyncify(et.evalFn, uint64(codePtr), 0, 0, 0)
+	_, err = et.callWithAsyncify(ctx, et.evalFn, uint64(codePtr), 0, 0, 0)
 	if err != nil {
-		return "", fmt.Errorf("eval failed: %w", err)
+		return "", et.fail(ctx, "eval", err)
 	}
 
 	// Flush stdout
 	if et.flushFn != nil {
-		et.flushFn.Call(et.ctx)
+		et.flushFn.Call(ctx)
 	}
 
 	return et.stdout.String(), nil
@@ -244,121 +319,204 @@ func (et *ExifTool) eval(code string) (string, error) {
 
 // ReadMetadata reads metadata from an image file.
 func (et *ExifTool) ReadMetadata(filePath string) (map[string]any, error) {
-	// Copy file to temp directory for WASI access
-	data, err := os.ReadFile(filePath)
+	return et.ReadMetadataContext(et.ctx, filePath)
+}
+
+// ReadMetadataContext reads metadata from an image file. If ctx is done
+// before the call finishes, the running evaluation is interrupted and the
+// returned error wraps ctx.Err(); see ErrInstanceBroken.
+func (et *ExifTool) ReadMetadataContext(ctx context.Context, filePath string) (map[string]any, error) {
+	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
+	defer f.Close()
 
-	tmpFile := et.tmpDir + "/input"
-	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
-		return nil, fmt.Errorf("failed to write temp file: %w", err)
-	}
-	defer os.Remove(tmpFile)
-
-	// Execute Perl code to extract metadata
-	code := `
-use Image::ExifTool;
-use JSON::PP;
-my $et = Image::ExifTool->new;
-my $info = $et->ImageInfo('/tmp/input');
-my %result;
-foreach my $tag (keys %$info) {
-    my $val = $$info{$tag};
-    if (ref($val) eq 'SCALAR') {
-        $result{$tag} = '[binary data]';
-    } else {
-        $result{$tag} = $val;
-    }
-}
-print JSON::PP->new->utf8->encode(\%result);
-`
-	output, err := et.eval(code)
+	opts, err := ReadOptions{}.forFile(filePath, f)
 	if err != nil {
 		return nil, err
 	}
+	return et.readMetadata(ctx, f, opts)
+}
 
-	var result map[string]any
-	if err := json.Unmarshal([]byte(output), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w (output: %s)", err, output)
-	}
+// ReadMetadataFromReader reads metadata from the image data provided by r.
+// The data is streamed directly into the sandbox, so callers holding uploads
+// or blobs in memory do not need to create a file of their own.
+func (et *ExifTool) ReadMetadataFromReader(ctx context.Context, r io.Reader) (map[string]any, error) {
+	return et.readMetadata(ctx, r, ReadOptions{})
+}
 
-	return result, nil
+// ReadMetadataFromBytes reads metadata from an in-memory image.
+func (et *ExifTool) ReadMetadataFromBytes(ctx context.Context, data []byte) (map[string]any, error) {
+	return et.readMetadata(ctx, bytes.NewReader(data), ReadOptions{})
 }
 
-// Version returns the ExifTool version.
-func (et *ExifTool) Version() (string, error) {
-	code := "use Image::ExifTool; print Image::ExifTool->VERSION;"
-	return et.eval(code)
+// fail marks the instance as broken after a wasm call failed and returns
+// the error to report. Interruptions by ctx are reported as ctx.Err().
+// The caller must hold et.mu.
+func (et *ExifTool) fail(ctx context.Context, op string, err error) error {
+	et.trapped = true
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s interrupted: %w: %w", op, ctxErr, ErrInstanceBroken)
+	}
+	return fmt.Errorf("%s failed: %w: %w", op, err, ErrInstanceBroken)
 }
 
-// WriteMetadata writes multiple tags to an image file.
-// If dstPath is empty, the source file is modified in place.
-func (et *ExifTool) WriteMetadata(srcPath string, dstPath string, tags map[string]any) error {
-	// Read source file
-	data, err := os.ReadFile(srcPath)
+// call invokes a routine of the ExifToolGo Perl package defined by
+// perlPreload and decodes its return value into result. The args are staged
+// as a JSON file in the sandbox, so the evaluated code only ever contains the
+// routine name and a generated path. A Perl error is returned as *PerlError.
+// The caller must hold et.mu.
+func (et *ExifTool) call(ctx context.Context, routine string, args any, result any) error {
+	data, err := json.Marshal(args)
 	if err != nil {
-		return fmt.Errorf("failed to read source file: %w", err)
+		return fmt.Errorf("failed to marshal arguments: %w", err)
 	}
 
-	// Write to temp input file
-	tmpInput := et.tmpDir + "/input"
-	if err := os.WriteFile(tmpInput, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temp input file: %w", err)
+	hostPath, guestPath := et.sandboxFile("args")
+	if err := stageFile(hostPath, bytes.NewReader(data)); err != nil {
+		return err
 	}
-	defer os.Remove(tmpInput)
+	defer os.Remove(hostPath)
 
-	// Convert tags to JSON
-	tagsJSON, err := json.Marshal(tags)
+	output, err := et.eval(ctx, "ExifToolGo::run('"+routine+"', '"+guestPath+"');")
 	if err != nil {
-		return fmt.Errorf("failed to marshal tags: %w", err)
+		return err
+	}
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Died   *string         `json:"died"`
+	}
+	if err := json.Unmarshal([]byte(output), &resp); err != nil {
+		return &PerlError{
+			Message: fmt.Sprintf("invalid response from %s: %v", routine, err),
+			Stderr:  et.stderr.String(),
+		}
+	}
+	if resp.Died != nil {
+		return &PerlError{
+			Message: strings.TrimSpace(*resp.Died),
+			Stderr:  et.stderr.String(),
+		}
 	}
 
-	// Execute Perl code to write metadata
-	code := fmt.Sprintf(`
-use Image::ExifTool;
-use JSON::PP;
-my $et = Image::ExifTool->new;
-my $tags = JSON::PP->new->utf8->decode('%s');
-foreach my $tag (keys %%$tags) {
-    $et->SetNewValue($tag, $tags->{$tag});
+	if result != nil {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("failed to parse result of %s: %w", routine, err)
+		}
+	}
+	return nil
 }
-my $result = $et->WriteInfo('/tmp/input', '/tmp/output');
-print $result;
-`, string(tagsJSON))
 
-	output, err := et.eval(code)
-	if err != nil {
-		return fmt.Errorf("failed to execute write: %w", err)
+// exifToolError returns an *ExifToolError for the error ExifTool reported
+// during op, or nil if there was none.
+// The caller must hold et.mu.
+func (et *ExifTool) exifToolError(op string, message string, warnings []string) error {
+	if message == "" {
+		return nil
 	}
+	return &ExifToolError{
+		Op:       op,
+		Message:  message,
+		Warnings: warnings,
+		Stderr:   et.stderr.String(),
+	}
+}
+
+// readMetadata stages the image data in the sandbox and extracts its metadata.
+func (et *ExifTool) readMetadata(ctx context.Context, r io.Reader, opts ReadOptions) (map[string]any, error) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
 
-	// Check result: 1=success, 2=success with warnings, 0=failure
-	if output == "0" {
-		return fmt.Errorf("exiftool write failed")
+	tmpFile, guestFile := et.sandboxFile("input")
+	if err := stageFile(tmpFile, r); err != nil {
+		return nil, err
 	}
+	defer os.Remove(tmpFile)
 
-	// Read output file
-	tmpOutput := et.tmpDir + "/output"
-	outputData, err := os.ReadFile(tmpOutput)
+	// Extract metadata
+	var result struct {
+		Tags     map[string]any `json:"tags"`
+		Error    string         `json:"error"`
+		Warnings []string       `json:"warnings"`
+	}
+	err := et.call(ctx, "read_metadata", opts.args(guestFile), &result)
 	if err != nil {
-		return fmt.Errorf("failed to read output file: %w", err)
+		return nil, err
 	}
-	defer os.Remove(tmpOutput)
-
-	// Determine destination path
-	dest := dstPath
-	if dest == "" {
-		dest = srcPath
+	if err := et.exifToolError("read", result.Error, result.Warnings); err != nil {
+		return nil, err
 	}
 
-	// Write to destination
-	if err := os.WriteFile(dest, outputData, 0644); err != nil {
-		return fmt.Errorf("failed to write destination file: %w", err)
-	}
+	return result.Tags, nil
+}
+
+// sandboxFile returns the host path and the matching guest path of a new
+// file in the sandbox. The name is unique for the lifetime of the instance,
+// so concurrent or failed calls never see each other's files.
+// The caller must hold et.mu.
+func (et *ExifTool) sandboxFile(name string) (hostPath, guestPath string) {
+	et.seq++
+	name += "-" + strconv.FormatUint(et.seq, 10)
+	return filepath.Join(et.tmpDir, name), "/tmp/" + name
+}
 
+// stageFile copies the contents of r into the sandbox file at hostPath.
+func stageFile(hostPath string, r io.Reader) error {
+	f, err := os.OpenFile(hostPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(hostPath)
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(hostPath)
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
 	return nil
 }
 
+// Version returns the ExifTool version.
+func (et *ExifTool) Version() (string, error) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	var version string
+	err := et.call(et.ctx, "version", map[string]any{}, &version)
+	return version, err
+}
+
+// WriteMetadata writes multiple tags to an image file.
+// If dstPath is empty, the source file is modified in place.
+// Values may be strings, numbers, lists of these such as []string for
+// list-type tags, ListEdit to add or remove list items, or []byte and
+// io.Reader for binary tags such as ICC_Profile, ThumbnailImage or a whole
+// XMP packet.
+// Use WriteMetadataWithOptions to find out which tags were actually set.
+func (et *ExifTool) WriteMetadata(srcPath string, dstPath string, tags map[string]any) error {
+	return et.WriteMetadataContext(et.ctx, srcPath, dstPath, tags)
+}
+
+// WriteMetadataContext writes multiple tags to an image file.
+// If dstPath is empty, the source file is modified in place. If ctx is done
+// before the call finishes, the running evaluation is interrupted, no file
+// is written and the returned error wraps ctx.Err(); see ErrInstanceBroken.
+func (et *ExifTool) WriteMetadataContext(ctx context.Context, srcPath string, dstPath string, tags map[string]any) error {
+	_, err := et.WriteMetadataWithOptions(ctx, srcPath, dstPath, tags, WriteOptions{})
+	return err
+}
+
+// WriteMetadataTo writes multiple tags to the image read from r and writes
+// the modified image to w. Nothing is written to w if ExifTool fails.
+func (et *ExifTool) WriteMetadataTo(ctx context.Context, r io.Reader, w io.Writer, tags map[string]any) error {
+	_, err := et.WriteMetadataToWithOptions(ctx, r, w, tags, WriteOptions{})
+	return err
+}
+
 // SetTag writes a single tag to an image file.
 // If dstPath is empty, the source file is modified in place.
 func (et *ExifTool) SetTag(srcPath string, dstPath string, tag string, value string) error {
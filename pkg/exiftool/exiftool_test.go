@@ -1,11 +1,15 @@
 package exiftool
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 )
@@ -147,6 +151,131 @@ func TestReadMetadataFileNotFound(t *testing.T) {
 	}
 }
 
+func TestReadMetadataFromReader(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	f, err := os.Open(filepath.Join("testdata", "test.jpg"))
+	if err != nil {
+		t.Fatalf("Failed to open test image: %v", err)
+	}
+	defer f.Close()
+
+	metadata, err := et.ReadMetadataFromReader(context.Background(), f)
+	if err != nil {
+		t.Fatalf("ReadMetadataFromReader failed: %v", err)
+	}
+
+	if fileType, ok := metadata["FileType"]; !ok || fileType != "JPEG" {
+		t.Errorf("FileType should be JPEG, got %v", fileType)
+	}
+}
+
+func TestReadMetadataFromBytes(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		t.Fatalf("Failed to read test image: %v", err)
+	}
+
+	fromBytes, err := et.ReadMetadataFromBytes(context.Background(), data)
+	if err != nil {
+		t.Fatalf("ReadMetadataFromBytes failed: %v", err)
+	}
+
+	fromPath, err := et.ReadMetadata(srcPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+
+	// Only compare tags that describe the image itself
+	stableTags := []string{"ImageWidth", "ImageHeight", "FileType", "MIMEType"}
+	if !compareMaps(extractTags(fromBytes, stableTags), extractTags(fromPath, stableTags)) {
+		t.Errorf("Metadata mismatch: bytes=%v path=%v", fromBytes, fromPath)
+	}
+}
+
+func TestReadMetadataContextCanceled(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = et.ReadMetadataContext(ctx, filepath.Join("testdata", "test.jpg"))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	// Nothing ran, so the instance must still be usable
+	if _, err := et.Version(); err != nil {
+		t.Errorf("Version failed after canceled call: %v", err)
+	}
+}
+
+func TestEvalInterruptedByDeadline(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// Stands in for a pathological file that never finishes parsing
+	et.mu.Lock()
+	_, err = et.eval(ctx, "1 while 1;")
+	et.mu.Unlock()
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if !errors.Is(err, ErrInstanceBroken) {
+		t.Errorf("Expected ErrInstanceBroken, got %v", err)
+	}
+	if et.healthy() {
+		t.Error("Instance should be marked as broken")
+	}
+
+	if _, err := et.ReadMetadata(filepath.Join("testdata", "test.jpg")); !errors.Is(err, ErrInstanceBroken) {
+		t.Errorf("Expected ErrInstanceBroken from broken instance, got %v", err)
+	}
+}
+
+func TestWriteMetadataContextCanceled(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+	err = et.WriteMetadataContext(ctx, filepath.Join("testdata", "test.jpg"), dstPath, map[string]any{"Artist": "Test"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if _, err := os.Stat(dstPath); !os.IsNotExist(err) {
+		t.Error("Destination should not be written when the call is canceled")
+	}
+}
+
 func TestWriteMetadataSourceNotFound(t *testing.T) {
 	et, err := New()
 	if err != nil {
@@ -267,6 +396,43 @@ func TestWriteMetadata(t *testing.T) {
 	}
 }
 
+func TestWriteMetadataTo(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	src, err := os.Open(filepath.Join("testdata", "test.jpg"))
+	if err != nil {
+		t.Fatalf("Failed to open test image: %v", err)
+	}
+	defer src.Close()
+
+	tags := map[string]any{
+		"Artist": "Stream Artist",
+	}
+
+	var out bytes.Buffer
+	if err := et.WriteMetadataTo(context.Background(), src, &out, tags); err != nil {
+		t.Fatalf("WriteMetadataTo failed: %v", err)
+	}
+
+	if out.Len() == 0 {
+		t.Fatal("Output should not be empty")
+	}
+
+	// Read back from memory and verify
+	metadata, err := et.ReadMetadataFromBytes(context.Background(), out.Bytes())
+	if err != nil {
+		t.Fatalf("ReadMetadataFromBytes failed: %v", err)
+	}
+
+	if artist, ok := metadata["Artist"]; !ok || artist != "Stream Artist" {
+		t.Errorf("Artist tag not set correctly: got %v", artist)
+	}
+}
+
 func TestWriteMetadataInPlace(t *testing.T) {
 	et, err := New()
 	if err != nil {
@@ -341,6 +507,159 @@ func TestSetTag(t *testing.T) {
 	}
 }
 
+func TestConcurrentReadWrite(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	// Prepare distinct images that can be told apart by their Artist tag
+	srcPath := filepath.Join("testdata", "test.jpg")
+	tmpDir := t.TempDir()
+	const numImages = 4
+	paths := make([]string, numImages)
+	for i := range paths {
+		paths[i] = filepath.Join(tmpDir, fmt.Sprintf("image%d.jpg", i))
+		if err := et.SetTag(srcPath, paths[i], "Artist", fmt.Sprintf("Artist %d", i)); err != nil {
+			t.Fatalf("SetTag failed: %v", err)
+		}
+	}
+
+	const numWorkers = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, numWorkers)
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			i := w % numImages
+			want := fmt.Sprintf("Artist %d", i)
+
+			if w%2 == 0 {
+				metadata, err := et.ReadMetadata(paths[i])
+				if err != nil {
+					errs <- fmt.Errorf("worker %d: ReadMetadata failed: %w", w, err)
+					return
+				}
+				if artist := metadata["Artist"]; artist != want {
+					errs <- fmt.Errorf("worker %d: expected Artist %q, got %v", w, want, artist)
+				}
+				return
+			}
+
+			dstPath := filepath.Join(tmpDir, fmt.Sprintf("worker%d.jpg", w))
+			comment := fmt.Sprintf("Worker %d", w)
+			if err := et.SetTag(paths[i], dstPath, "Comment", comment); err != nil {
+				errs <- fmt.Errorf("worker %d: SetTag failed: %w", w, err)
+				return
+			}
+			metadata, err := et.ReadMetadata(dstPath)
+			if err != nil {
+				errs <- fmt.Errorf("worker %d: ReadMetadata failed: %w", w, err)
+				return
+			}
+			if artist := metadata["Artist"]; artist != want {
+				errs <- fmt.Errorf("worker %d: expected Artist %q, got %v", w, want, artist)
+			}
+			if got := metadata["Comment"]; got != comment {
+				errs <- fmt.Errorf("worker %d: expected Comment %q, got %v", w, comment, got)
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestWriteMetadataSpecialCharacters(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"SingleQuote", "O'Brien"},
+		{"DoubleQuote", `Say "cheese"`},
+		{"Backslash", `C:\Users\photo\new.jpg`},
+		{"Sigils", "$HOME @ARGV %ENV"},
+		{"PerlInjection", `'); print "pwned"; ('`},
+		{"JSONInjection", `"}; print "pwned"; {"`},
+		{"NonASCII", "Café 日本語"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+			tags := map[string]any{
+				"Artist":  tt.value,
+				"Comment": tt.value,
+			}
+			if err := et.WriteMetadata(srcPath, dstPath, tags); err != nil {
+				t.Fatalf("WriteMetadata failed: %v", err)
+			}
+
+			metadata, err := et.ReadMetadata(dstPath)
+			if err != nil {
+				t.Fatalf("ReadMetadata failed: %v", err)
+			}
+			for tag := range tags {
+				if got := metadata[tag]; got != tt.value {
+					t.Errorf("Tag %s: expected %q, got %v", tag, tt.value, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteMetadataRawBytes(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"NUL", "before\x00after"},
+		{"NonUTF8", "latin1 caf\xe9 \xff\xfe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+			if err := et.SetTag(srcPath, dstPath, "Comment", tt.value); err != nil {
+				t.Fatalf("SetTag failed: %v", err)
+			}
+
+			// The JPEG comment is stored verbatim, so the exact bytes must
+			// be present in the output file
+			data, err := os.ReadFile(dstPath)
+			if err != nil {
+				t.Fatalf("Failed to read output file: %v", err)
+			}
+			if !bytes.Contains(data, []byte(tt.value)) {
+				t.Errorf("Output file does not contain the comment bytes %q", tt.value)
+			}
+		})
+	}
+}
+
 func TestWriteMetadataGolden(t *testing.T) {
 	et, err := New()
 	if err != nil {
@@ -512,3 +831,70 @@ func compareValues(a, b any) bool {
 	}
 	return a == b
 }
+
+// legacyReadScript is the per-call script ReadMetadata evaluated before the
+// read routine was defined once at initialization. It is kept to compare
+// both approaches in BenchmarkSequentialReads.
+const legacyReadScript = `
+use Image::ExifTool;
+use JSON::PP;
+my $et = Image::ExifTool->new;
+my $info = $et->ImageInfo('%s');
+my %%result;
+foreach my $tag (keys %%$info) {
+    my $val = $$info{$tag};
+    if (ref($val) eq 'SCALAR') {
+        $result{$tag} = '[binary data]';
+    } else {
+        $result{$tag} = $val;
+    }
+}
+print JSON::PP->new->utf8->encode(\%%result);
+`
+
+// BenchmarkSequentialReads measures 1,000 sequential reads of the same file
+// with a freshly parsed script per call and with the persistent Perl subs.
+func BenchmarkSequentialReads(b *testing.B) {
+	const reads = 1000
+
+	et, err := New()
+	if err != nil {
+		b.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "test.jpg"))
+	if err != nil {
+		b.Fatalf("Failed to read test image: %v", err)
+	}
+
+	b.Run("PerCallScript", func(b *testing.B) {
+		et.mu.Lock()
+		defer et.mu.Unlock()
+
+		tmpFile, guestFile := et.sandboxFile("input")
+		if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+			b.Fatalf("Failed to stage test image: %v", err)
+		}
+		defer os.Remove(tmpFile)
+
+		code := fmt.Sprintf(legacyReadScript, guestFile)
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < reads; j++ {
+				if _, err := et.eval(et.ctx, code); err != nil {
+					b.Fatalf("eval failed: %v", err)
+				}
+			}
+		}
+	})
+
+	b.Run("PersistentSubs", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < reads; j++ {
+				if _, err := et.ReadMetadataFromBytes(et.ctx, data); err != nil {
+					b.Fatalf("ReadMetadataFromBytes failed: %v", err)
+				}
+			}
+		}
+	})
+}
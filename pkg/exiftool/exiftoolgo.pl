@@ -0,0 +1,386 @@
+# Perl side of exiftool-go.
+#
+# This file is evaluated once when an instance is initialized. It loads
+# Image::ExifTool and defines the routines the Go side calls for every
+# operation, so that per-call work is limited to the operation itself.
+#
+# Arguments never appear in Perl source: the Go side stages them as a JSON
+# file in the sandbox and evaluates a fixed call to run() with its path.
+# run() prints a single JSON object, either {"result": ...} with the value
+# returned by the routine or {"died": ...} with the Perl error message.
+
+use Image::ExifTool;
+use JSON::PP;
+
+package ExifToolGo;
+
+our $et   = Image::ExifTool->new;
+our $json = JSON::PP->new->utf8;
+
+# ExifTool returns UTF-8 byte strings, which must not be encoded again
+our $out_json = JSON::PP->new;
+
+# Objects of this form refer to a sandbox file holding a value that JSON
+# cannot carry byte for byte, such as a string that is not valid UTF-8.
+our $FILE_REF = 'exiftoolgo:file';
+
+# Objects of this form refer to a sandbox file holding binary data, which is
+# passed to ExifTool as a scalar reference.
+our $BINARY_REF = 'exiftoolgo:binary';
+
+# Objects of this form hold list items to add and remove instead of a new
+# value, like -TAG+=VALUE and -TAG-=VALUE, or a value to set only where the
+# old one is removed, like -TAG-=OLD -TAG=NEW.
+our $EDIT = 'exiftoolgo:edit';
+
+# Reset the shared ExifTool object so that nothing leaks between calls.
+sub reset_tool {
+    $et->ClearOptions();
+    $et->SetNewValue();
+}
+
+# Read a whole file as bytes.
+sub read_file {
+    my ($file) = @_;
+    open(my $fh, '<', $file) or die "failed to open $file: $!\n";
+    binmode($fh);
+    local $/;
+    my $data = <$fh>;
+    close($fh);
+    return defined $data ? $data : '';
+}
+
+# Turn decoded JSON into the values ExifTool expects: strings become UTF-8
+# byte strings and file references are replaced by the file contents, or a
+# reference to them for binary data.
+sub decode_value {
+    my ($val) = @_;
+    if (ref($val) eq 'HASH') {
+        if (exists $$val{$FILE_REF} and keys(%$val) == 1) {
+            return read_file($$val{$FILE_REF});
+        }
+        if (exists $$val{$BINARY_REF} and keys(%$val) == 1) {
+            my $data = read_file($$val{$BINARY_REF});
+            return \$data;
+        }
+        $$val{$_} = decode_value($$val{$_}) foreach keys %$val;
+    } elsif (ref($val) eq 'ARRAY') {
+        $_ = decode_value($_) foreach @$val;
+    } elsif (defined $val and not ref($val)) {
+        utf8::encode($val);
+    }
+    return $val;
+}
+
+# Entry point for the Go side: call the named routine with the arguments
+# from the JSON file and print its result.
+sub run {
+    my ($name, $args_file) = @_;
+    my $result = eval {
+        my $args = decode_value($json->decode(read_file($args_file)));
+        my $sub = ExifToolGo->can($name) or die "unknown routine $name\n";
+        reset_tool();
+        $sub->($args);
+    };
+    if ($@) {
+        print $out_json->encode({ died => "$@" });
+    } else {
+        print $out_json->encode({ result => $result });
+    }
+}
+
+# Collect the values of a tag and its duplicates, e.g. Warning,
+# "Warning (1)", from an info hash.
+sub messages {
+    my ($info, $name) = @_;
+    my @keys = grep { /^\Q$name\E(?: \((\d+)\))?$/ } keys %$info;
+    my %order = map {; $_ => (/\((\d+)\)$/ ? $1 + 1 : 0) } @keys;
+    return [ map { $$info{$_} } sort { $order{$a} <=> $order{$b} } @keys ];
+}
+
+# Return the ExifTool version.
+sub version {
+    return '' . Image::ExifTool->VERSION;
+}
+
+# Apply the read options shared by the read routines.
+# Args: numeric, duplicates, unknown, lists, date_format
+sub set_read_options {
+    my ($args) = @_;
+    $et->Options(
+        PrintConv  => $$args{numeric}    ? 0 : 1,
+        Duplicates => $$args{duplicates} ? 1 : 0,
+        Unknown    => $$args{unknown}    ? 1 : 0,
+        # Return list-type tags such as Keywords as arrays, like -json
+        ListJoin   => $$args{lists}      ? undef : ', ',
+    );
+    if (defined $$args{date_format} and length $$args{date_format}) {
+        $et->Options(DateFormat => $$args{date_format});
+    }
+}
+
+# Return the list of tags to extract, with excluded tags prefixed by '-'.
+# Args: tags, exclude
+sub requested_tags {
+    my ($args) = @_;
+    return (@{ $$args{tags} || [] }, map { "-$_" } @{ $$args{exclude} || [] });
+}
+
+# Extract the requested tags of a file like ImageInfo. The System tags such
+# as FileName and FileModifyDate describe the sandbox copy, so those given in
+# file_tags are replaced by the raw values of the caller's file before they
+# are converted, and those given as null are removed.
+# Args: file, file_tags, tags, exclude
+sub extract_info {
+    my ($args) = @_;
+    my @tags = requested_tags($args);
+    $et->ExtractInfo($$args{file}, @tags);
+    my $files = $$args{file_tags} || {};
+    foreach my $key (keys %{ $$et{VALUE} }) {
+        my $name = Image::ExifTool::GetTagName($key);
+        next unless exists $$files{$name} and $et->GetGroup($key, 1) eq 'System';
+        if (defined $$files{$name}) {
+            $$et{VALUE}{$key} = $$files{$name};
+        } else {
+            $et->DeleteTag($key);
+        }
+    }
+    return $et->GetInfo(@tags);
+}
+
+# Return the metadata of a file along with the error and warnings ExifTool
+# reported for it. With groups set, keys are prefixed by the group names of
+# those families, like the -G option.
+# Args: file, groups and the read options
+sub read_metadata {
+    my ($args) = @_;
+    set_read_options($args);
+    my $info = extract_info($args);
+    my $groups = $$args{groups};
+    my %tags;
+    foreach my $key (sort keys %$info) {
+        my $val = tag_value($$info{$key});
+        my $name = $key;
+        if (defined $groups and length $groups) {
+            my $group = $et->GetGroup($key, $groups);
+            $name = "$group:" . Image::ExifTool::GetTagName($key);
+            # Duplicates within the same group keep their index suffix
+            $name = "$group:$key" if exists $tags{$name};
+        }
+        $tags{$name} = $val;
+    }
+    return {
+        tags     => \%tags,
+        error    => $$info{Error},
+        warnings => messages($info, 'Warning'),
+    };
+}
+
+# Return the tags of a file in the order they appear in the file, with their
+# groups, ID, table and both the raw and the print-converted value.
+# Args: file and the read options except numeric
+sub read_tags {
+    my ($args) = @_;
+    set_read_options($args);
+    my $info = extract_info($args);
+    my @tags;
+    foreach my $key ($et->GetTagList($info, 'File')) {
+        my @groups = $et->GetGroup($key);
+        my $id = scalar $et->GetTagID($key);
+        $id = sprintf('0x%.4x', $id) if defined $id and $id =~ /^\d+$/;
+        my $index = $key =~ / \((\d+)\)$/ ? $1 : 0;
+        push @tags, {
+            name        => Image::ExifTool::GetTagName($key),
+            description => scalar $et->GetDescription($key),
+            group0      => $groups[0],
+            group1      => $groups[1],
+            group2      => $groups[2],
+            id          => defined $id ? "$id" : '',
+            table       => scalar $et->GetTableName($key),
+            value       => tag_value(scalar $et->GetValue($key, 'ValueConv')),
+            print_value => tag_value(scalar $et->GetValue($key, 'PrintConv')),
+            index       => $index + 0,
+        };
+    }
+    return {
+        tags     => \@tags,
+        error    => $$info{Error},
+        warnings => messages($info, 'Warning'),
+    };
+}
+
+# Return a tag value in a form that can be encoded as JSON. Binary data is
+# replaced by its size, like the exiftool command line does.
+sub tag_value {
+    my ($val) = @_;
+    return $val unless ref($val) eq 'SCALAR';
+    my $len = $$val =~ /^Binary data (\d+) bytes$/ ? $1 : length($$val);
+    return "(Binary data $len bytes)";
+}
+
+# Write the value of a tag, usually a binary one such as ThumbnailImage, to
+# a file like the -b option. Returns whether the tag was found and its size,
+# along with the error and warnings.
+# Args: file, tag, dst
+sub extract_binary {
+    my ($args) = @_;
+    $et->Options(Binary => 1);
+    my $info = $et->ImageInfo($$args{file}, $$args{tag});
+    my %result = (
+        error    => $$info{Error},
+        warnings => messages($info, 'Warning'),
+    );
+    my ($key) = grep { !/^(Error|Warning)\b/ } $et->GetTagList($info, 'File');
+    return +{ %result, found => JSON::PP::false() } unless defined $key;
+
+    my $val = $$info{$key};
+    $val = $$val if ref($val) eq 'SCALAR';
+    $val = join("\n", @$val) if ref($val) eq 'ARRAY';
+    die "$key is not a binary value\n" if ref($val);
+
+    open(my $fh, '>:raw', $$args{dst}) or die "Can't create $$args{dst}: $!\n";
+    print $fh $val;
+    close($fh) or die "Can't write $$args{dst}: $!\n";
+    return +{ %result, found => JSON::PP::true(), size => length($val) };
+}
+
+# Set the given tags and write the result to a new file.
+# Returns the WriteInfo status, the tags that were set or rejected along with
+# ExifTool's reason, and the error and warnings. With strict set, nothing is
+# written if any tag was rejected and the result is flagged as aborted.
+# Args: src, dst, tags, strict
+sub write_metadata {
+    my ($args) = @_;
+    my $tags = $$args{tags};
+    my (@set, @rejected, @warnings);
+    foreach my $tag (sort keys %$tags) {
+        my ($num, $err) = set_value($tag, $$tags{$tag});
+        if ($num) {
+            push @set, $tag;
+            push @warnings, "$tag: $err" if $err;
+        } else {
+            push @rejected, { tag => $tag, reason => $err || 'No value set' };
+        }
+    }
+    return write_file($args, \@set, \@rejected, \@warnings);
+}
+
+# Set the new value of a tag. Edits remove values with DelValue, then add
+# list items with AddValue and finally set a value, which for a removed value
+# means it is only replaced where it currently has that value.
+# Returns the number of tags set and the error or warning, like SetNewValue.
+sub set_value {
+    my ($tag, $val) = @_;
+    unless (ref($val) eq 'HASH' and exists $$val{$EDIT}) {
+        return $et->SetNewValue($tag, $val);
+    }
+    my $edit = $$val{$EDIT};
+    my @calls;
+    push @calls, [ $$edit{remove}, DelValue => 1 ] if $$edit{remove} and @{ $$edit{remove} };
+    push @calls, [ $$edit{add}, AddValue => 1 ] if $$edit{add} and @{ $$edit{add} };
+    push @calls, [ $$edit{value} ] if exists $$edit{value};
+    my ($num, $err) = (0, undef);
+    foreach my $i (0 .. $#calls) {
+        my ($value, %options) = @{ $calls[$i] };
+        # Later calls must not replace the values set by the earlier ones
+        $options{Replace} = 0 if $i;
+        my ($n, $e) = $et->SetNewValue($tag, $value, %options);
+        return (0, $e) unless $n;
+        $num += $n;
+        $err = $e if $e and not $err;
+    }
+    return ($num, $err);
+}
+
+# Delete the given tags or groups, like -TAG= on the command line, keeping
+# the tags in keep by copying them back from the source file like
+# "-all= -tagsFromFile @ -ICC_Profile". Returns the same result as
+# write_metadata, with the deleted tags reported as set.
+# Args: src, dst, tags, keep, strict
+sub delete_tags {
+    my ($args) = @_;
+    my (@set, @rejected, @warnings);
+    foreach my $tag (@{ $$args{tags} || [] }) {
+        my ($num, $err) = $et->SetNewValue($tag);
+        if ($num) {
+            push @set, $tag;
+            push @warnings, "$tag: $err" if $err;
+        } else {
+            push @rejected, { tag => $tag, reason => $err || 'No tag deleted' };
+        }
+    }
+    my @keep = @{ $$args{keep} || [] };
+    if (@keep) {
+        # Errors reading the source are reported again by WriteInfo
+        my $info = $et->SetNewValuesFromFile($$args{src}, @keep);
+        push @warnings, @{ messages($info, 'Warning') };
+    }
+    return write_file($args, \@set, \@rejected, \@warnings);
+}
+
+# Copy tags from another file, like -tagsFromFile. Tags may redirect groups
+# such as "EXIF:all>XMP:all" and are excluded when prefixed by '-'; no tags
+# copies all of them. Returns the same result as write_metadata, with the
+# copied tags reported as set.
+# Args: src, dst, from, tags, strict
+sub copy_metadata {
+    my ($args) = @_;
+    my $info = $et->SetNewValuesFromFile($$args{from}, @{ $$args{tags} || [] });
+    my @warnings = @{ messages($info, 'Warning') };
+    if ($$info{Error}) {
+        return {
+            set      => [],
+            rejected => [],
+            warnings => \@warnings,
+            status   => 0,
+            error    => $$info{Error},
+        };
+    }
+    my %names = map {; Image::ExifTool::GetTagName($_) => 1 } keys %$info;
+    my @set = grep { defined scalar $et->GetNewValue($_) } sort keys %names;
+    return write_file($args, \@set, [], \@warnings);
+}
+
+# Write the new values set on $et from src to dst and return the result of
+# a write routine. The write mode restricts which tags are created or edited
+# and nothing is written if the condition is false for src.
+# Args: src, dst, strict, mode, condition
+sub write_file {
+    my ($args, $set, $rejected, $warnings) = @_;
+    my %result = (set => $set, rejected => $rejected, warnings => $warnings);
+    if ($$args{strict} and @$rejected) {
+        return +{ %result, status => 0, aborted => JSON::PP::true() };
+    }
+    if (length($$args{condition} // '') and not check_condition($$args{src}, $$args{condition})) {
+        return +{ %result, status => 0, skipped => JSON::PP::true() };
+    }
+    $et->Options(WriteMode => $$args{mode}) if length($$args{mode} // '');
+    my $status = $et->WriteInfo($$args{src}, $$args{dst});
+    push @$warnings, @{ messages($et->GetInfo('Warning'), 'Warning') };
+    return +{ %result, status => $status + 0, error => $et->GetValue('Error') };
+}
+
+# Evaluate a Perl expression against the metadata of a file like the -if
+# option. Tags are referred to as $TAG or ${GROUP:TAG}, with a "#" suffix
+# for the value without print conversion; missing tags are undefined.
+sub check_condition {
+    my ($file, $cond) = @_;
+    $et->ExtractInfo($file);
+    my (@values, %index);
+    $cond =~ s{\$\{?((?:[-\w]+:)*[-\w]+#?)\}?}{
+        my $token = $1;
+        unless (exists $index{$token}) {
+            (my $name = $token) =~ s/#$//;
+            my $print = $name eq $token ? 1 : 0;
+            my $info = $et->GetInfo($name, { PrintConv => $print, Duplicates => 0 });
+            my ($key) = grep { !/^(Error|Warning)\b/ } sort keys %$info;
+            push @values, defined $key ? $$info{$key} : undef;
+            $index{$token} = $#values;
+        }
+        "\$values[$index{$token}]";
+    }ge;
+    my $result = eval $cond;
+    die "Condition: $@" if $@;
+    return $result;
+}
+1;
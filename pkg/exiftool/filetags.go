@@ -0,0 +1,58 @@
+package exiftool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileTags returns the raw values of the System group tags of the caller's
+// file at path, which replace those ExifTool reports for the sandbox copy.
+// Dates are Unix times and FilePermissions is the Unix st_mode, as ExifTool
+// stores them. Tags the platform can't report are nil and are removed.
+func fileTags(path string, f *os.File) (map[string]any, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	st := statFile(info)
+
+	unix := func(t time.Time) any {
+		if t.IsZero() {
+			return nil
+		}
+		return t.Unix()
+	}
+
+	return map[string]any{
+		"FileName":            filepath.Base(path),
+		"Directory":           filepath.Dir(path),
+		"FileModifyDate":      unix(info.ModTime()),
+		"FileAccessDate":      unix(st.atime),
+		"FileInodeChangeDate": unix(st.ctime),
+		"FilePermissions":     st.mode,
+	}, nil
+}
+
+// fileStat holds the file attributes os.FileInfo doesn't report portably.
+// Zero times are unknown.
+type fileStat struct {
+	atime time.Time
+	ctime time.Time
+	mode  uint32
+}
+
+// unixMode returns the Unix st_mode of a file mode.
+func unixMode(mode os.FileMode) uint32 {
+	m := uint32(mode.Perm())
+	switch {
+	case mode.IsDir():
+		m |= 0o040000
+	case mode&os.ModeSymlink != 0:
+		m |= 0o120000
+	case mode.IsRegular():
+		m |= 0o100000
+	}
+	return m
+}
@@ -0,0 +1,20 @@
+package exiftool
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// statFile returns the access and inode change times and the mode of a file.
+func statFile(info os.FileInfo) fileStat {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileStat{mode: unixMode(info.Mode())}
+	}
+	return fileStat{
+		atime: time.Unix(st.Atimespec.Unix()),
+		ctime: time.Unix(st.Ctimespec.Unix()),
+		mode:  uint32(st.Mode),
+	}
+}
@@ -0,0 +1,20 @@
+package exiftool
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// statFile returns the access and inode change times and the mode of a file.
+func statFile(info os.FileInfo) fileStat {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileStat{mode: unixMode(info.Mode())}
+	}
+	return fileStat{
+		atime: time.Unix(st.Atim.Unix()),
+		ctime: time.Unix(st.Ctim.Unix()),
+		mode:  st.Mode,
+	}
+}
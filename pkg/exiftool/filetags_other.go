@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows
+
+package exiftool
+
+import "os"
+
+// statFile returns the mode of a file. Access and inode change times are
+// not available on this platform.
+func statFile(info os.FileInfo) fileStat {
+	return fileStat{mode: unixMode(info.Mode())}
+}
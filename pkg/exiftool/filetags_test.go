@@ -0,0 +1,104 @@
+package exiftool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// copyTestImage copies testdata/test.jpg to a temporary directory and sets
+// its modification time to mtime.
+func copyTestImage(t *testing.T, mtime time.Time) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "test.jpg"))
+	if err != nil {
+		t.Fatalf("Failed to read test image: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(path, data, 0640); err != nil {
+		t.Fatalf("Failed to write test image: %v", err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("Failed to set times: %v", err)
+	}
+	return path
+}
+
+func TestFileTags(t *testing.T) {
+	mtime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)
+	path := copyTestImage(t, mtime)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open test image: %v", err)
+	}
+	defer f.Close()
+
+	tags, err := fileTags(path, f)
+	if err != nil {
+		t.Fatalf("fileTags failed: %v", err)
+	}
+	if tags["FileName"] != "photo.jpg" || tags["Directory"] != filepath.Dir(path) {
+		t.Errorf("FileName, Directory = %v, %v", tags["FileName"], tags["Directory"])
+	}
+	if tags["FileModifyDate"] != mtime.Unix() {
+		t.Errorf("FileModifyDate = %v, want %d", tags["FileModifyDate"], mtime.Unix())
+	}
+	if mode, ok := tags["FilePermissions"].(uint32); !ok || mode&0o777 != 0o640 {
+		t.Errorf("FilePermissions = %v, want mode 640", tags["FilePermissions"])
+	}
+}
+
+func TestReadFileTags(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	mtime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)
+	path := copyTestImage(t, mtime)
+
+	check := func(name string, fileName, directory, modifyDate any) {
+		t.Helper()
+		if fileName != "photo.jpg" {
+			t.Errorf("%s: FileName = %v, want photo.jpg", name, fileName)
+		}
+		if directory != filepath.Dir(path) {
+			t.Errorf("%s: Directory = %v, want %s", name, directory, filepath.Dir(path))
+		}
+		s, _ := modifyDate.(string)
+		got, err := time.Parse("2006:01:02 15:04:05-07:00", s)
+		if err != nil || !got.Equal(mtime) {
+			t.Errorf("%s: FileModifyDate = %v, want %v", name, modifyDate, mtime)
+		}
+	}
+
+	metadata, err := et.ReadMetadata(path)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	check("ReadMetadata", metadata["FileName"], metadata["Directory"], metadata["FileModifyDate"])
+	if metadata["FilePermissions"] != "-rw-r-----" {
+		t.Errorf("FilePermissions = %v, want -rw-r-----", metadata["FilePermissions"])
+	}
+
+	opts := ReadOptions{Tags: []string{"System:all"}}
+	metadata, err = et.ReadMetadataWithOptions(context.Background(), path, opts)
+	if err != nil {
+		t.Fatalf("ReadMetadataWithOptions failed: %v", err)
+	}
+	check("ReadMetadataWithOptions", metadata["FileName"], metadata["Directory"], metadata["FileModifyDate"])
+
+	tags, err := et.ReadTags(context.Background(), path, opts)
+	if err != nil {
+		t.Fatalf("ReadTags failed: %v", err)
+	}
+	values := make(map[string]any)
+	for _, tag := range tags {
+		values[tag.Name] = tag.PrintValue
+	}
+	check("ReadTags", values["FileName"], values["Directory"], values["FileModifyDate"])
+}
@@ -0,0 +1,17 @@
+package exiftool
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// statFile returns the access time and the mode of a file. Windows has no
+// inode change time.
+func statFile(info os.FileInfo) fileStat {
+	st := fileStat{mode: unixMode(info.Mode())}
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		st.atime = time.Unix(0, data.LastAccessTime.Nanoseconds())
+	}
+	return st
+}
@@ -0,0 +1,440 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	ratType  = reflect.TypeOf((*big.Rat)(nil))
+)
+
+// fieldSpec is the parsed "exif" struct tag of a field.
+type fieldSpec struct {
+	name      string
+	list      bool
+	omitEmpty bool
+}
+
+// parseField returns the spec of a struct field, or false if the field is
+// not mapped to a tag.
+func parseField(f reflect.StructField) (fieldSpec, bool) {
+	if !f.IsExported() {
+		return fieldSpec{}, false
+	}
+	tag, ok := f.Tag.Lookup("exif")
+	if tag == "-" {
+		return fieldSpec{}, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if !ok || name == "" {
+		name = f.Name
+	}
+	spec := fieldSpec{name: name}
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "list":
+			spec.list = true
+		case "omitempty":
+			spec.omitEmpty = true
+		}
+	}
+	return spec, true
+}
+
+// isNested reports whether f is an untagged struct field to be flattened.
+func isNested(f reflect.StructField) bool {
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	_, tagged := f.Tag.Lookup("exif")
+	return t.Kind() == reflect.Struct && t != timeType && !tagged
+}
+
+// structValue returns the struct v points to.
+func structValue(v any, fn string) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("exiftool: %s requires a non-nil pointer to a struct, got %T", fn, v)
+	}
+	return rv.Elem(), nil
+}
+
+// Unmarshal stores tag values of m in the struct pointed to by v according
+// to its "exif" struct tags. Fields of tags missing from m are left
+// unchanged. Values that can't be converted to the field type return a
+// *ValueError.
+//
+// Struct fields are mapped to tags with the "exif" struct tag:
+//
+//	type Photo struct {
+//		Taken       time.Time `exif:"EXIF:DateTimeOriginal"`
+//		Orientation int       `exif:"Orientation#"`
+//		Keywords    []string  `exif:"Keywords,list"`
+//		Exposure    *big.Rat  `exif:"ExposureTime,omitempty"`
+//		Camera      Camera    // nested structs are flattened
+//	}
+//
+// The name may include a group and a "#" suffix for the value without print
+// conversion. Exported fields without a tag use the field name and fields
+// tagged "-" are ignored. Options are:
+//
+//   - list: the tag is a list; a string field receives the items joined by ", "
+//   - omitempty: Marshal omits the field if it has its zero value
+//
+// Supported field types are string, signed and unsigned integers, floats,
+// []string, time.Time, *big.Rat, any, pointers to these and nested structs.
+func Unmarshal(m Metadata, v any) error {
+	rv, err := structValue(v, "Unmarshal")
+	if err != nil {
+		return err
+	}
+	_, err = unmarshalStruct(m, rv)
+	return err
+}
+
+// unmarshalStruct populates the fields of rv and reports whether any tag
+// was found.
+func unmarshalStruct(m Metadata, rv reflect.Value) (bool, error) {
+	found := false
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		spec, ok := parseField(f)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+
+		if isNested(f) {
+			if f.Type.Kind() != reflect.Pointer {
+				set, err := unmarshalStruct(m, fv)
+				if err != nil {
+					return false, err
+				}
+				found = found || set
+				continue
+			}
+			nested := reflect.New(f.Type.Elem())
+			if !fv.IsNil() {
+				nested.Elem().Set(fv.Elem())
+			}
+			set, err := unmarshalStruct(m, nested.Elem())
+			if err != nil {
+				return false, err
+			}
+			if set {
+				fv.Set(nested)
+				found = true
+			}
+			continue
+		}
+
+		if !m.Has(spec.name) {
+			continue
+		}
+		if err := unmarshalField(m, spec, fv); err != nil {
+			return false, err
+		}
+		found = true
+	}
+	return found, nil
+}
+
+// unmarshalField converts the value of a tag to the type of fv.
+func unmarshalField(m Metadata, spec fieldSpec, fv reflect.Value) error {
+	tag := spec.name
+	t := fv.Type()
+
+	if t.Kind() == reflect.Pointer && t != ratType {
+		ptr := reflect.New(t.Elem())
+		if err := unmarshalField(m, spec, ptr.Elem()); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+
+	switch {
+	case t == timeType:
+		tm, err := m.Time(tag)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(tm))
+		return nil
+	case t == ratType:
+		r, err := m.Rational(tag)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(r))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		var s string
+		var err error
+		if spec.list {
+			var items []string
+			items, err = m.Strings(tag)
+			s = strings.Join(items, ", ")
+		} else {
+			s, err = m.String(tag)
+		}
+		if err != nil {
+			return err
+		}
+		fv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := m.Int(tag)
+		if err != nil {
+			return err
+		}
+		if fv.OverflowInt(n) {
+			return &ValueError{Tag: tag, Value: m[tag], Type: t.String()}
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := m.Int(tag)
+		if err != nil {
+			return err
+		}
+		if n < 0 || fv.OverflowUint(uint64(n)) {
+			return &ValueError{Tag: tag, Value: m[tag], Type: t.String()}
+		}
+		fv.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		f, err := m.Float(tag)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			return fmt.Errorf("exiftool: unsupported field type %s for tag %s", t, tag)
+		}
+		items, err := m.Strings(tag)
+		if err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			s.Index(i).SetString(item)
+		}
+		fv.Set(s)
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			return fmt.Errorf("exiftool: unsupported field type %s for tag %s", t, tag)
+		}
+		if v := m[tag]; v != nil {
+			fv.Set(reflect.ValueOf(v))
+		}
+	default:
+		return fmt.Errorf("exiftool: unsupported field type %s for tag %s", t, tag)
+	}
+	return nil
+}
+
+// Marshal returns the tags of the struct v, or a pointer to it, as a map
+// for WriteMetadata, the inverse of Unmarshal. Nil pointers and slices are
+// omitted, as are zero values of fields with the omitempty option.
+// Values keep their Go types, which WriteMetadata converts: time.Time to
+// dates with OffsetTime tags and *big.Rat to fractions.
+func Marshal(v any) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("exiftool: Marshal requires a struct or a pointer to a struct, got %T", v)
+	}
+
+	tags := make(map[string]any)
+	if err := marshalStruct(rv, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// marshalStruct adds the tags of the fields of rv to tags.
+func marshalStruct(rv reflect.Value, tags map[string]any) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		spec, ok := parseField(f)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+
+		if isNested(f) {
+			if f.Type.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if err := marshalStruct(fv, tags); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if spec.omitEmpty && fv.IsZero() {
+			continue
+		}
+		value, ok, err := marshalField(spec, fv)
+		if err != nil {
+			return err
+		}
+		if ok {
+			tags[spec.name] = value
+		}
+	}
+	return nil
+}
+
+// marshalField returns the tag value of a field, or false if it is unset.
+func marshalField(spec fieldSpec, fv reflect.Value) (any, bool, error) {
+	t := fv.Type()
+
+	switch {
+	case t == timeType:
+		return fv.Interface(), true, nil
+	case t == ratType:
+		if fv.IsNil() {
+			return nil, false, nil
+		}
+		return fv.Interface(), true, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if fv.IsNil() {
+			return nil, false, nil
+		}
+		return marshalField(spec, fv.Elem())
+	case reflect.String:
+		return fv.String(), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fv.Int(), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fv.Uint(), true, nil
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), true, nil
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			break
+		}
+		if fv.IsNil() {
+			return nil, false, nil
+		}
+		items := make([]string, fv.Len())
+		for i := range items {
+			items[i] = fv.Index(i).String()
+		}
+		return items, true, nil
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			break
+		}
+		if fv.IsNil() {
+			return nil, false, nil
+		}
+		return fv.Interface(), true, nil
+	}
+	return nil, false, fmt.Errorf("exiftool: unsupported field type %s for tag %s", t, spec.name)
+}
+
+// structTags returns the tags to extract for the fields of t. Date/time
+// fields also request the tags holding their time zone.
+func structTags(t reflect.Type) []string {
+	var tags []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		spec, ok := parseField(f)
+		if !ok {
+			continue
+		}
+		if isNested(f) {
+			nested := f.Type
+			if nested.Kind() == reflect.Pointer {
+				nested = nested.Elem()
+			}
+			tags = append(tags, structTags(nested)...)
+			continue
+		}
+
+		name := strings.TrimSuffix(spec.name, "#")
+		tags = append(tags, name)
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft == timeType {
+			prefix, base := "", name
+			if i := strings.LastIndex(name, ":"); i >= 0 {
+				prefix, base = name[:i+1], name[i+1:]
+			}
+			if offsetTag, ok := offsetTags[base]; ok {
+				tags = append(tags, prefix+offsetTag)
+			}
+			tags = append(tags, "TimeZone")
+		}
+	}
+	return tags
+}
+
+// tagsMetadata returns the Metadata of tags read with duplicates, keyed by
+// name, family 0 and family 1 group, and with a "#" suffix for the raw
+// values. Names without a group refer to the tag ExifTool gives priority.
+func tagsMetadata(tags []Tag) Metadata {
+	m := make(Metadata)
+	add := func(key string, tag Tag) {
+		if _, ok := m[key]; ok {
+			return
+		}
+		m[key] = tag.PrintValue
+		m[key+"#"] = tag.Value
+	}
+	for _, tag := range tags {
+		if tag.Index == 0 {
+			add(tag.Name, tag)
+		}
+	}
+	for _, tag := range tags {
+		add(tag.Group0+":"+tag.Name, tag)
+		add(tag.Group1+":"+tag.Name, tag)
+	}
+	return m
+}
+
+// ReadInto reads the tags mapped by the "exif" struct tags of the struct
+// pointed to by v from an image file and stores them as Unmarshal does.
+// Only these tags are extracted.
+func (et *ExifTool) ReadInto(ctx context.Context, filePath string, v any) error {
+	rv, err := structValue(v, "ReadInto")
+	if err != nil {
+		return err
+	}
+	names := structTags(rv.Type())
+	if len(names) == 0 {
+		return errors.New("exiftool: ReadInto requires a struct with at least one tag")
+	}
+
+	tags, err := et.ReadTags(ctx, filePath, ReadOptions{Tags: names, Duplicates: true, Lists: true})
+	if err != nil {
+		return err
+	}
+	_, err = unmarshalStruct(tagsMetadata(tags), rv)
+	return err
+}
@@ -0,0 +1,207 @@
+package exiftool
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testCamera struct {
+	Make  string `exif:"Make"`
+	Model string `exif:"Model,omitempty"`
+}
+
+type testPhoto struct {
+	Taken       time.Time  `exif:"EXIF:DateTimeOriginal"`
+	Orientation int        `exif:"Orientation#"`
+	ISO         uint16     `exif:"ISO"`
+	FNumber     float64    `exif:"FNumber"`
+	Exposure    *big.Rat   `exif:"ExposureTime,omitempty"`
+	Keywords    []string   `exif:"Keywords,list"`
+	Subject     string     `exif:"Subject,list"`
+	Software    *string    `exif:"Software"`
+	Camera      testCamera // flattened
+	Lens        *testCamera
+	Ignored     string `exif:"-"`
+	internal    string
+}
+
+func TestUnmarshal(t *testing.T) {
+	m := Metadata{
+		"EXIF:DateTimeOriginal":   "2008:05:30 15:56:01",
+		"EXIF:OffsetTimeOriginal": "+09:00",
+		"Orientation#":            float64(1),
+		"ISO":                     float64(100),
+		"FNumber":                 7.1,
+		"ExposureTime":            "1/160",
+		"Keywords":                []any{"sunset", "beach"},
+		"Subject":                 []any{"sea", "sky"},
+		"Software":                "GIMP 2.4.5",
+		"Make":                    "Canon",
+		"Model":                   "Canon EOS 40D",
+		"Ignored":                 "x",
+	}
+
+	var p testPhoto
+	if err := Unmarshal(m, &p); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	want := time.Date(2008, 5, 30, 15, 56, 1, 0, time.FixedZone("", 9*3600))
+	if !p.Taken.Equal(want) {
+		t.Errorf("Taken = %v, want %v", p.Taken, want)
+	}
+	if p.Orientation != 1 || p.ISO != 100 || p.FNumber != 7.1 {
+		t.Errorf("numbers = %d %d %v", p.Orientation, p.ISO, p.FNumber)
+	}
+	if p.Exposure == nil || p.Exposure.Cmp(big.NewRat(1, 160)) != 0 {
+		t.Errorf("Exposure = %v, want 1/160", p.Exposure)
+	}
+	if !reflect.DeepEqual(p.Keywords, []string{"sunset", "beach"}) {
+		t.Errorf("Keywords = %v", p.Keywords)
+	}
+	if p.Subject != "sea, sky" {
+		t.Errorf("Subject = %q", p.Subject)
+	}
+	if p.Software == nil || *p.Software != "GIMP 2.4.5" {
+		t.Errorf("Software = %v", p.Software)
+	}
+	if p.Camera.Make != "Canon" || p.Camera.Model != "Canon EOS 40D" {
+		t.Errorf("Camera = %+v", p.Camera)
+	}
+	if p.Lens == nil || p.Lens.Make != "Canon" {
+		t.Errorf("Lens = %+v", p.Lens)
+	}
+	if p.Ignored != "" {
+		t.Errorf("Ignored = %q, want empty", p.Ignored)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var p testPhoto
+	if err := Unmarshal(Metadata{"ISO": "high"}, &p); err == nil {
+		t.Error("Unmarshal of invalid ISO succeeded, want error")
+	}
+	if err := Unmarshal(Metadata{"ISO": float64(70000)}, &p); err == nil {
+		t.Error("Unmarshal of overflowing ISO succeeded, want error")
+	}
+	if err := Unmarshal(Metadata{}, p); err == nil {
+		t.Error("Unmarshal into non-pointer succeeded, want error")
+	}
+
+	var bad struct {
+		Values []int `exif:"Values"`
+	}
+	if err := Unmarshal(Metadata{"Values": []any{1.0}}, &bad); err == nil {
+		t.Error("Unmarshal into []int succeeded, want error")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	software := "GIMP 2.4.5"
+	p := testPhoto{
+		Taken:       time.Date(2008, 5, 30, 15, 56, 1, 0, time.FixedZone("", 9*3600)),
+		Orientation: 6,
+		ISO:         100,
+		FNumber:     7.1,
+		Keywords:    []string{"sunset", "beach"},
+		Subject:     "sea",
+		Software:    &software,
+		Camera:      testCamera{Make: "Canon"},
+		Ignored:     "x",
+	}
+
+	tags, err := Marshal(&p)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	want := map[string]any{
+		"EXIF:DateTimeOriginal": p.Taken,
+		"Orientation#":          int64(6),
+		"ISO":                   uint64(100),
+		"FNumber":               7.1,
+		"Keywords":              []string{"sunset", "beach"},
+		"Subject":               "sea",
+		"Software":              "GIMP 2.4.5",
+		"Make":                  "Canon",
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("Marshal = %#v, want %#v", tags, want)
+	}
+}
+
+func TestReadInto(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	var p testPhoto
+	err = et.ReadInto(context.Background(), filepath.Join("testdata", "test.jpg"), &p)
+	if err != nil {
+		t.Fatalf("ReadInto failed: %v", err)
+	}
+
+	want := time.Date(2008, 5, 30, 15, 56, 1, 0, time.UTC)
+	if !p.Taken.Equal(want) {
+		t.Errorf("Taken = %v, want %v", p.Taken, want)
+	}
+	if p.Orientation != 1 || p.ISO != 100 || p.FNumber != 7.1 {
+		t.Errorf("numbers = %d %d %v", p.Orientation, p.ISO, p.FNumber)
+	}
+	if p.Exposure == nil || p.Exposure.Cmp(big.NewRat(1, 160)) != 0 {
+		t.Errorf("Exposure = %v, want 1/160", p.Exposure)
+	}
+	if p.Camera.Make != "Canon" || p.Camera.Model != "Canon EOS 40D" {
+		t.Errorf("Camera = %+v", p.Camera)
+	}
+	if p.Keywords != nil {
+		t.Errorf("Keywords = %v, want nil", p.Keywords)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	in := testPhoto{
+		Taken:       time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Orientation: 6,
+		ISO:         400,
+		FNumber:     2.8,
+		Exposure:    big.NewRat(1, 250),
+		Keywords:    []string{"sunset", "beach"},
+		Camera:      testCamera{Make: "Test", Model: "Model X"},
+	}
+	tags, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	dstPath := filepath.Join(t.TempDir(), "out.jpg")
+	if err := et.WriteMetadata(filepath.Join("testdata", "test.jpg"), dstPath, tags); err != nil {
+		t.Fatalf("WriteMetadata failed: %v", err)
+	}
+
+	var out testPhoto
+	if err := et.ReadInto(context.Background(), dstPath, &out); err != nil {
+		t.Fatalf("ReadInto failed: %v", err)
+	}
+	if !out.Taken.Equal(in.Taken) || out.Orientation != 6 || out.ISO != 400 || out.FNumber != 2.8 {
+		t.Errorf("ReadInto = %+v, want %+v", out, in)
+	}
+	if out.Exposure == nil || out.Exposure.Cmp(in.Exposure) != 0 {
+		t.Errorf("Exposure = %v, want %v", out.Exposure, in.Exposure)
+	}
+	if !reflect.DeepEqual(out.Keywords, in.Keywords) || out.Camera != in.Camera {
+		t.Errorf("ReadInto = %+v, want %+v", out, in)
+	}
+}
@@ -0,0 +1,274 @@
+package exiftool
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTagNotFound is returned by Metadata accessors for missing tags.
+var ErrTagNotFound = errors.New("exiftool: tag not found")
+
+// ValueError is returned by Metadata accessors when a tag value can't be
+// converted to the requested type.
+type ValueError struct {
+	// Tag is the requested tag.
+	Tag string
+	// Value is the value as returned by ReadMetadata.
+	Value any
+	// Type is the requested type, e.g. "int" or "time".
+	Type string
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("exiftool: tag %s: cannot convert %v (%T) to %s", e.Tag, e.Value, e.Value, e.Type)
+}
+
+// Metadata wraps the map returned by ReadMetadata with typed accessors.
+// Values decoded from JSON may be numbers, strings or lists for the same
+// tag depending on the file, so the accessors accept every representation
+// that can be converted losslessly and return a *ValueError otherwise.
+//
+// ReadMetadata joins the items of list-type tags such as Keywords into one
+// string. Read with ReadOptions.Lists to get them as arrays, so that Strings
+// returns the separate items:
+//
+//	m, err := et.ReadMetadataWithOptions(ctx, "photo.jpg", exiftool.ReadOptions{Lists: true})
+//	...
+//	taken, err := exiftool.Metadata(m).Time("DateTimeOriginal")
+//	keywords, err := exiftool.Metadata(m).Strings("Keywords")
+type Metadata map[string]any
+
+// exifTimeLayouts are the date/time formats used by ExifTool, most specific
+// first.
+var exifTimeLayouts = []string{
+	"2006:01:02 15:04:05.999999999Z07:00",
+	"2006:01:02 15:04:05Z07:00",
+	"2006:01:02 15:04:05.999999999",
+	"2006:01:02 15:04:05",
+	"2006:01:02 15:04Z07:00",
+	"2006:01:02 15:04",
+	"2006:01:02",
+}
+
+// offsetTags maps date/time tags to the EXIF tags holding their UTC offset.
+var offsetTags = map[string]string{
+	"DateTimeOriginal": "OffsetTimeOriginal",
+	"CreateDate":       "OffsetTimeDigitized",
+	"ModifyDate":       "OffsetTime",
+}
+
+// Has reports whether tag is present.
+func (m Metadata) Has(tag string) bool {
+	_, ok := m[tag]
+	return ok
+}
+
+// get returns the value of tag or ErrTagNotFound.
+func (m Metadata) get(tag string) (any, error) {
+	v, ok := m[tag]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTagNotFound, tag)
+	}
+	return v, nil
+}
+
+// String returns the value of tag as a string. Numbers are formatted
+// without loss; lists are an error, use Strings for them.
+func (m Metadata) String(tag string) (string, error) {
+	v, err := m.get(tag)
+	if err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", &ValueError{Tag: tag, Value: v, Type: "string"}
+}
+
+// Strings returns the items of a list-type tag such as Keywords or Subject,
+// which are arrays when read with ReadOptions.Lists. A tag with a single
+// value, including the joined items of a list read without that option, is
+// returned as a list with one item.
+func (m Metadata) Strings(tag string) ([]string, error) {
+	v, err := m.get(tag)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := v.([]any)
+	if !ok {
+		s, err := m.String(tag)
+		if err != nil {
+			return nil, &ValueError{Tag: tag, Value: v, Type: "[]string"}
+		}
+		return []string{s}, nil
+	}
+	out := make([]string, len(items))
+	for i, item := range items {
+		s, err := Metadata{tag: item}.String(tag)
+		if err != nil {
+			return nil, &ValueError{Tag: tag, Value: v, Type: "[]string"}
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// Int returns the value of tag as an integer. Numbers with a fractional part
+// are an error.
+func (m Metadata) Int(tag string) (int64, error) {
+	v, err := m.get(tag)
+	if err != nil {
+		return 0, err
+	}
+	switch v := v.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), nil
+		}
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	return 0, &ValueError{Tag: tag, Value: v, Type: "int"}
+}
+
+// Float returns the value of tag as a float. Rational strings such as the
+// printed ExposureTime "1/160" are evaluated.
+func (m Metadata) Float(tag string) (float64, error) {
+	v, err := m.get(tag)
+	if err != nil {
+		return 0, err
+	}
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, nil
+		}
+		if r, ok := parseRational(s); ok {
+			f, _ := r.Float64()
+			return f, nil
+		}
+	}
+	return 0, &ValueError{Tag: tag, Value: v, Type: "float"}
+}
+
+// Rational returns the value of tag as an exact rational number. Both
+// fractions such as "1/160" and decimals are accepted.
+func (m Metadata) Rational(tag string) (*big.Rat, error) {
+	v, err := m.get(tag)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case float64:
+		// The shortest decimal of the float is the value ExifTool printed,
+		// e.g. 7.1 rather than the binary approximation of it
+		if r, ok := new(big.Rat).SetString(strconv.FormatFloat(v, 'g', -1, 64)); ok {
+			return r, nil
+		}
+	case string:
+		if r, ok := parseRational(strings.TrimSpace(v)); ok {
+			return r, nil
+		}
+	}
+	return nil, &ValueError{Tag: tag, Value: v, Type: "rational"}
+}
+
+// parseRational parses "n/d" or a decimal number.
+func parseRational(s string) (*big.Rat, bool) {
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		if strings.TrimSpace(den) == "0" {
+			return nil, false
+		}
+		s = strings.TrimSpace(num) + "/" + strings.TrimSpace(den)
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// Time returns the value of a date/time tag such as DateTimeOriginal.
+// If the value has no time zone, the matching OffsetTime* tag is used
+// (OffsetTimeOriginal for DateTimeOriginal, OffsetTimeDigitized for
+// CreateDate and OffsetTime for ModifyDate), falling back to TimeZone.
+// Times without any zone information are returned in UTC.
+// Values formatted with ReadOptions.DateFormat can't be parsed.
+func (m Metadata) Time(tag string) (time.Time, error) {
+	v, err := m.get(tag)
+	if err != nil {
+		return time.Time{}, err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, &ValueError{Tag: tag, Value: v, Type: "time"}
+	}
+	s = strings.TrimSpace(s)
+
+	for _, layout := range exifTimeLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if strings.Contains(layout, "Z07:00") {
+			return t, nil
+		}
+		if loc := m.offset(tag); loc != nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc), nil
+		}
+		return t, nil
+	}
+	return time.Time{}, &ValueError{Tag: tag, Value: v, Type: "time"}
+}
+
+// offset returns the zone for a date/time tag from its OffsetTime* tag or
+// TimeZone, or nil if there is none. A group prefix on tag is applied to the
+// offset tag as well.
+func (m Metadata) offset(tag string) *time.Location {
+	prefix, name := "", tag
+	if i := strings.LastIndex(tag, ":"); i >= 0 {
+		prefix, name = tag[:i+1], tag[i+1:]
+	}
+
+	var candidates []string
+	if offsetTag, ok := offsetTags[name]; ok {
+		candidates = append(candidates, prefix+offsetTag, offsetTag)
+	}
+	candidates = append(candidates, prefix+"TimeZone", "TimeZone")
+
+	for _, c := range candidates {
+		s, ok := m[c].(string)
+		if !ok {
+			continue
+		}
+		if loc := parseOffset(s); loc != nil {
+			return loc
+		}
+	}
+	return nil
+}
+
+// parseOffset parses a UTC offset such as "+09:00", "-0530" or "Z".
+func parseOffset(s string) *time.Location {
+	s = strings.TrimSpace(s)
+	if s == "Z" {
+		return time.UTC
+	}
+	for _, layout := range []string{"-07:00", "-0700", "-07"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			_, offset := t.Zone()
+			return time.FixedZone(s, offset)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package exiftool
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testMetadata() Metadata {
+	return Metadata{
+		"Make":               "Canon",
+		"ISO":                float64(100),
+		"FNumber":            7.1,
+		"ExposureTime":       "1/160",
+		"Keywords":           []any{"sunset", "beach"},
+		"Subject":            "sea",
+		"DateTimeOriginal":   "2008:05:30 15:56:01",
+		"OffsetTimeOriginal": "+09:00",
+		"ModifyDate":         "2008:07:31 10:38:11",
+		"CreateDate":         "2008:05:30 15:56:01.25-05:00",
+		"EXIF:ModifyDate":    "2008:07:31 10:38:11",
+		"EXIF:OffsetTime":    "-0330",
+		"GPSLatitude":        map[string]any{"value": 1},
+	}
+}
+
+func TestMetadataString(t *testing.T) {
+	m := testMetadata()
+
+	tests := map[string]string{"Make": "Canon", "ISO": "100", "FNumber": "7.1"}
+	for tag, want := range tests {
+		got, err := m.String(tag)
+		if err != nil || got != want {
+			t.Errorf("String(%q) = %q, %v; want %q", tag, got, err, want)
+		}
+	}
+
+	var verr *ValueError
+	if _, err := m.String("Keywords"); !errors.As(err, &verr) || verr.Tag != "Keywords" {
+		t.Errorf("String(Keywords) error = %v, want *ValueError", err)
+	}
+	if _, err := m.String("Missing"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("String(Missing) error = %v, want ErrTagNotFound", err)
+	}
+	if !m.Has("Make") || m.Has("Missing") {
+		t.Error("Has returned wrong result")
+	}
+}
+
+func TestMetadataStrings(t *testing.T) {
+	m := testMetadata()
+
+	got, err := m.Strings("Keywords")
+	if err != nil || !reflect.DeepEqual(got, []string{"sunset", "beach"}) {
+		t.Errorf("Strings(Keywords) = %v, %v", got, err)
+	}
+	got, err = m.Strings("Subject")
+	if err != nil || !reflect.DeepEqual(got, []string{"sea"}) {
+		t.Errorf("Strings(Subject) = %v, %v", got, err)
+	}
+	if _, err := m.Strings("GPSLatitude"); err == nil {
+		t.Error("Strings(GPSLatitude) succeeded, want error")
+	}
+}
+
+func TestMetadataNumbers(t *testing.T) {
+	m := testMetadata()
+
+	if n, err := m.Int("ISO"); err != nil || n != 100 {
+		t.Errorf("Int(ISO) = %d, %v", n, err)
+	}
+	if _, err := m.Int("FNumber"); err == nil {
+		t.Error("Int(FNumber) succeeded, want error")
+	}
+	if _, err := m.Int("Make"); err == nil {
+		t.Error("Int(Make) succeeded, want error")
+	}
+
+	if f, err := m.Float("FNumber"); err != nil || f != 7.1 {
+		t.Errorf("Float(FNumber) = %v, %v", f, err)
+	}
+	if f, err := m.Float("ExposureTime"); err != nil || f != 1.0/160 {
+		t.Errorf("Float(ExposureTime) = %v, %v", f, err)
+	}
+
+	r, err := m.Rational("ExposureTime")
+	if err != nil || r.Cmp(big.NewRat(1, 160)) != 0 {
+		t.Errorf("Rational(ExposureTime) = %v, %v", r, err)
+	}
+	r, err = m.Rational("ISO")
+	if err != nil || r.Cmp(big.NewRat(100, 1)) != 0 {
+		t.Errorf("Rational(ISO) = %v, %v", r, err)
+	}
+	r, err = m.Rational("FNumber")
+	if err != nil || r.Cmp(big.NewRat(71, 10)) != 0 {
+		t.Errorf("Rational(FNumber) = %v, %v", r, err)
+	}
+	r, err = Metadata{"ExposureTime": 0.00625}.Rational("ExposureTime")
+	if err != nil || r.Cmp(big.NewRat(1, 160)) != 0 {
+		t.Errorf("Rational(ExposureTime) of a number = %v, %v", r, err)
+	}
+	if _, err := m.Rational("Make"); err == nil {
+		t.Error("Rational(Make) succeeded, want error")
+	}
+}
+
+func TestMetadataTime(t *testing.T) {
+	m := testMetadata()
+
+	tests := []struct {
+		tag  string
+		want time.Time
+	}{
+		{"DateTimeOriginal", time.Date(2008, 5, 30, 15, 56, 1, 0, time.FixedZone("", 9*3600))},
+		{"ModifyDate", time.Date(2008, 7, 31, 10, 38, 11, 0, time.UTC)},
+		{"CreateDate", time.Date(2008, 5, 30, 15, 56, 1, 250000000, time.FixedZone("", -5*3600))},
+		{"EXIF:ModifyDate", time.Date(2008, 7, 31, 10, 38, 11, 0, time.FixedZone("", -(3*3600+1800)))},
+	}
+	for _, tt := range tests {
+		got, err := m.Time(tt.tag)
+		if err != nil {
+			t.Errorf("Time(%q) error: %v", tt.tag, err)
+			continue
+		}
+		_, gotOffset := got.Zone()
+		_, wantOffset := tt.want.Zone()
+		if !got.Equal(tt.want) || gotOffset != wantOffset {
+			t.Errorf("Time(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+
+	if _, err := m.Time("Make"); err == nil {
+		t.Error("Time(Make) succeeded, want error")
+	}
+	if _, err := m.Time("ISO"); err == nil {
+		t.Error("Time(ISO) succeeded, want error")
+	}
+}
+
+func TestMetadataFromReadMetadata(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	data, err := et.ReadMetadata(filepath.Join("testdata", "test.jpg"))
+	if err != nil {
+		t.Fatalf("Failed to read metadata: %v", err)
+	}
+	m := Metadata(data)
+
+	if iso, err := m.Int("ISO"); err != nil || iso != 100 {
+		t.Errorf("Int(ISO) = %d, %v", iso, err)
+	}
+	if r, err := m.Rational("ExposureTime"); err != nil || r.Cmp(big.NewRat(1, 160)) != 0 {
+		t.Errorf("Rational(ExposureTime) = %v, %v", r, err)
+	}
+	want := time.Date(2008, 5, 30, 15, 56, 1, 0, time.UTC)
+	if got, err := m.Time("DateTimeOriginal"); err != nil || !got.Equal(want) {
+		t.Errorf("Time(DateTimeOriginal) = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !plan9
+
+package exiftool
+
+import (
+	"errors"
+	"syscall"
+)
+
+// crossDevice reports whether a rename failed because the paths are on
+// different file systems.
+func crossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
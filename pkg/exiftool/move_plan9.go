@@ -0,0 +1,14 @@
+package exiftool
+
+import (
+	"errors"
+	"os"
+)
+
+// crossDevice reports whether a rename may have failed because the paths
+// are on different file systems. Plan 9 has no EXDEV, so any failed rename
+// is retried as a copy.
+func crossDevice(err error) bool {
+	var linkErr *os.LinkError
+	return errors.As(err, &linkErr)
+}
@@ -0,0 +1,249 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrFileExists is reported by Organize for a file whose target already
+// exists when the template has no %c copy number. Existing files are never
+// overwritten.
+var ErrFileExists = errors.New("exiftool: target file exists")
+
+// OrganizeOptions configures Organize.
+type OrganizeOptions struct {
+	// DateFormat is a strftime format for date/time tags in the template,
+	// like -d. As with ExifTool, it may contain the file name codes with a
+	// doubled percent sign, e.g. "%Y/%m/%Y%m%d_%H%M%S%%-c.%%e".
+	DateFormat string
+
+	// DryRun only plans the moves. No file or directory is touched.
+	DryRun bool
+}
+
+// Move is a file move planned or performed by Organize.
+type Move struct {
+	// Src is the path of the file as passed to Organize.
+	Src string
+	// Dst is the target path. It equals Src if the file is already in place
+	// and is empty if no target could be computed.
+	Dst string
+	// Err is why the file was not moved, e.g. an error wrapping
+	// ErrTagNotFound or ErrFileExists.
+	Err error
+}
+
+// templateTag matches the tag references of an Organize template: $TAG,
+// ${TAG} or ${GROUP:TAG}, with a "#" suffix for the raw value, and "$$" for
+// a dollar sign.
+var templateTag = regexp.MustCompile(`\$(?:\$|\{((?:[-\w]*\w:)?[-\w]*\w#?)\}|((?:[-\w]*\w:)?[-\w]*\w#?))`)
+
+// fileCode matches the file name codes of an Organize template.
+var fileCode = regexp.MustCompile(`%(?:([-+]?)(\d*)c|[fe%])`)
+
+// Organize moves files to paths computed from their metadata, like writing
+// FileName with the exiftool command line, e.g.
+// '-FileName<${DateTimeOriginal}%-c.%e' -d %Y/%m/%Y%m%d_%H%M%S.
+//
+// The template refers to tags as $TAG or ${GROUP:TAG}; a "#" suffix
+// selects the raw value and "$$" is a dollar sign. Date/time values are
+// formatted with OrganizeOptions.DateFormat. The result may then contain
+// these codes:
+//
+//	%f  the file name without extension
+//	%e  the extension without the dot
+//	%c  a copy number, empty for the first candidate and 1, 2, ... for
+//	    the following ones; "%-c" and "%+c" prefix it with "-" or "_", and
+//	    a width such as "%3c" pads it with zeros
+//	%%  a percent sign
+//
+// A relative target is relative to the directory of the file, and missing
+// directories are created. Without %c, a file whose target exists is not
+// moved; with it, the first free name is used. Targets planned for earlier
+// files count as taken, so a dry run reports the same moves.
+//
+// One Move is returned for each path, in order. Errors of single files are
+// reported in Move.Err; the returned error is only set if the instance
+// failed or ctx was done, together with the moves made so far.
+func (et *ExifTool) Organize(ctx context.Context, paths []string, template string, opts OrganizeOptions) ([]Move, error) {
+	var names []string
+	for _, m := range templateTag.FindAllStringSubmatch(template, -1) {
+		if name := strings.TrimSuffix(m[1]+m[2], "#"); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	taken := make(map[string]bool)
+	freed := make(map[string]bool)
+	exists := func(path string) bool {
+		if taken[path] {
+			return true
+		}
+		if freed[path] {
+			return false
+		}
+		_, err := os.Lstat(path)
+		return err == nil
+	}
+
+	moves := make([]Move, 0, len(paths))
+	for _, src := range paths {
+		move := Move{Src: src}
+		dst, err := et.organizeTarget(ctx, src, template, names, opts, exists)
+		if err != nil && (ctx.Err() != nil || errors.Is(err, ErrInstanceBroken)) {
+			return moves, err
+		}
+		move.Dst = dst
+		if err == nil && dst != src && !opts.DryRun {
+			err = moveFile(src, dst)
+		}
+		if err == nil && dst != src {
+			taken[filepath.Clean(dst)] = true
+			freed[filepath.Clean(src)] = true
+			delete(taken, filepath.Clean(src))
+		}
+		move.Err = err
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+// organizeTarget returns the target path of the file at src, or src if the
+// file is already in place.
+func (et *ExifTool) organizeTarget(ctx context.Context, src string, template string, names []string, opts OrganizeOptions, exists func(string) bool) (string, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var m Metadata
+	if len(names) > 0 {
+		tags, err := et.ReadTags(ctx, src, ReadOptions{Tags: names, Duplicates: true, DateFormat: opts.DateFormat})
+		if err != nil {
+			return "", err
+		}
+		m = tagsMetadata(tags)
+	}
+
+	var missing error
+	name := templateTag.ReplaceAllStringFunc(template, func(ref string) string {
+		sub := templateTag.FindStringSubmatch(ref)
+		tag := sub[1] + sub[2]
+		if tag == "" {
+			return "$"
+		}
+		value, err := templateValue(m, tag)
+		if err != nil && missing == nil {
+			missing = err
+		}
+		return value
+	})
+	if missing != nil {
+		return "", missing
+	}
+
+	ext := filepath.Ext(src)
+	base := strings.TrimSuffix(filepath.Base(src), ext)
+	expand := func(n int) string {
+		return fileCode.ReplaceAllStringFunc(name, func(code string) string {
+			switch code {
+			case "%f":
+				return base
+			case "%e":
+				return strings.TrimPrefix(ext, ".")
+			case "%%":
+				return "%"
+			}
+			if n == 0 {
+				return ""
+			}
+			sub := fileCode.FindStringSubmatch(code)
+			width, _ := strconv.Atoi(sub[2])
+			num := fmt.Sprintf("%0*d", width, n)
+			switch sub[1] {
+			case "-":
+				return "-" + num
+			case "+":
+				return "_" + num
+			}
+			return num
+		})
+	}
+
+	hasCopy := false
+	for _, sub := range fileCode.FindAllStringSubmatch(name, -1) {
+		if strings.HasSuffix(sub[0], "c") {
+			hasCopy = true
+		}
+	}
+
+	for n := 0; ; n++ {
+		dst := expand(n)
+		if dst == "" {
+			return "", errors.New("exiftool: empty target file name")
+		}
+		if !filepath.IsAbs(dst) {
+			dst = filepath.Join(filepath.Dir(src), dst)
+		}
+		if filepath.Clean(dst) == filepath.Clean(src) {
+			return src, nil
+		}
+		if !exists(filepath.Clean(dst)) {
+			return dst, nil
+		}
+		if target, err := os.Stat(dst); err == nil && os.SameFile(info, target) {
+			return src, nil
+		}
+		if !hasCopy {
+			return dst, fmt.Errorf("%w: %s", ErrFileExists, dst)
+		}
+	}
+}
+
+// templateValue returns the value of a tag referenced by a template. Tag
+// names are case-insensitive, as with ExifTool, and list items are joined
+// by commas.
+func templateValue(m Metadata, tag string) (string, error) {
+	if !m.Has(tag) {
+		for key := range m {
+			if strings.EqualFold(key, tag) {
+				tag = key
+				break
+			}
+		}
+	}
+	items, err := m.Strings(tag)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(items, ", "), nil
+}
+
+// moveFile moves the file at src to the new path dst, creating missing
+// directories. Across file systems, the file is copied and then removed.
+func moveFile(src string, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	// Rename replaces existing files, so check again right before it
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%w: %s", ErrFileExists, dst)
+	}
+
+	err := os.Rename(src, dst)
+	if err != nil && crossDevice(err) {
+		if err = copyFile(src, dst); err == nil {
+			err = os.Remove(src)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOrganize(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	mtime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)
+	src1 := copyTestImage(t, mtime)
+	src2 := filepath.Join(filepath.Dir(src1), "copy.jpg")
+	if err := copyFile(src1, src2); err != nil {
+		t.Fatalf("Failed to copy test image: %v", err)
+	}
+	dir := filepath.Dir(src1)
+	paths := []string{src1, src2}
+	opts := OrganizeOptions{DateFormat: "%Y/%m/%Y%m%d_%H%M%S%%-c.%%e", DryRun: true}
+
+	want := []string{
+		filepath.Join(dir, "2008", "05", "20080530_155601.jpg"),
+		filepath.Join(dir, "2008", "05", "20080530_155601-1.jpg"),
+	}
+
+	moves, err := et.Organize(context.Background(), paths, "$DateTimeOriginal", opts)
+	if err != nil {
+		t.Fatalf("Organize failed: %v", err)
+	}
+	for i, move := range moves {
+		if move.Err != nil || move.Src != paths[i] || move.Dst != want[i] {
+			t.Errorf("dry run move %d = %+v, want %s", i, move, want[i])
+		}
+		if _, err := os.Stat(paths[i]); err != nil {
+			t.Errorf("dry run moved %s: %v", paths[i], err)
+		}
+	}
+
+	opts.DryRun = false
+	moves, err = et.Organize(context.Background(), paths, "$DateTimeOriginal", opts)
+	if err != nil {
+		t.Fatalf("Organize failed: %v", err)
+	}
+	for i, move := range moves {
+		if move.Err != nil || move.Dst != want[i] {
+			t.Errorf("move %d = %+v, want %s", i, move, want[i])
+		}
+		if _, err := os.Stat(want[i]); err != nil {
+			t.Errorf("target %s missing: %v", want[i], err)
+		}
+	}
+
+	// Dates are read from the moved file, not the sandbox copy
+	moves, err = et.Organize(context.Background(), want[:1], "${FileModifyDate}_$Model.%e", OrganizeOptions{DateFormat: "%Y"})
+	if err != nil {
+		t.Fatalf("Organize failed: %v", err)
+	}
+	if dst := filepath.Join(dir, "2008", "05", "2021_Canon EOS 40D.jpg"); moves[0].Err != nil || moves[0].Dst != dst {
+		t.Errorf("move = %+v, want %s", moves[0], dst)
+	}
+
+	moves, err = et.Organize(context.Background(), want[1:], "$GPSLatitude.%e", OrganizeOptions{})
+	if err != nil {
+		t.Fatalf("Organize failed: %v", err)
+	}
+	if !errors.Is(moves[0].Err, ErrTagNotFound) {
+		t.Errorf("move without tag = %+v, want ErrTagNotFound", moves[0])
+	}
+}
+
+func TestOrganizeFileNames(t *testing.T) {
+	// Templates without tags don't read metadata
+	var et *ExifTool
+
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a.jpg", "b.jpg", "c.png", "taken.jpg"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"out/photo%+3c.%e", []string{"out/photo.jpg", "out/photo_001.jpg", "out/photo.png", "out/photo_002.jpg"}},
+		{"%f.%e", []string{"a.jpg", "b.jpg", "c.png", "taken.jpg"}},
+		{"taken.jpg", []string{"", "", "", "taken.jpg"}},
+		{"100%%_%f$$.%e", []string{"100%_a$.jpg", "100%_b$.jpg", "100%_c$.png", "100%_taken$.jpg"}},
+	}
+	for _, tt := range tests {
+		moves, err := et.Organize(context.Background(), paths, tt.template, OrganizeOptions{DryRun: true})
+		if err != nil {
+			t.Fatalf("%s: Organize failed: %v", tt.template, err)
+		}
+		for i, move := range moves {
+			if tt.want[i] == "" {
+				if !errors.Is(move.Err, ErrFileExists) {
+					t.Errorf("%s: move %d = %+v, want ErrFileExists", tt.template, i, move)
+				}
+				continue
+			}
+			if want := filepath.Join(dir, tt.want[i]); move.Err != nil || move.Dst != want {
+				t.Errorf("%s: move %d = %+v, want %s", tt.template, i, move, want)
+			}
+		}
+	}
+
+	moves, err := et.Organize(context.Background(), paths[:2], "out/%f%-c.%e", OrganizeOptions{})
+	if err != nil {
+		t.Fatalf("Organize failed: %v", err)
+	}
+	for i, move := range moves {
+		data, err := os.ReadFile(move.Dst)
+		if move.Err != nil || err != nil || string(data) != filepath.Base(paths[i]) {
+			t.Errorf("move %d = %+v, read %q, %v", i, move, data, err)
+		}
+		if _, err := os.Stat(paths[i]); !os.IsNotExist(err) {
+			t.Errorf("source %s still exists", paths[i])
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package exiftool
+
+import "os"
+
+// chownLike does nothing, as files have no Unix owner on this platform.
+func chownLike(f *os.File, info os.FileInfo) {}
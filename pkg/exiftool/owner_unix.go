@@ -0,0 +1,16 @@
+//go:build unix
+
+package exiftool
+
+import (
+	"os"
+	"syscall"
+)
+
+// chownLike gives f the owner and group of the file described by info.
+// Only root may change the owner, so errors are ignored.
+func chownLike(f *os.File, info os.FileInfo) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		f.Chown(int(st.Uid), int(st.Gid))
+	}
+}
@@ -0,0 +1,103 @@
+//go:build unix
+
+package exiftool
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// appendRewrite is a rewrite function for writeFile that appends to the file.
+func appendRewrite(r io.Reader, w io.Writer) (*WriteResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(append(data, " rewritten"...))
+	return &WriteResult{Changed: true}, err
+}
+
+func TestWriteKeepsOwner(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing the owner of a file requires root")
+	}
+
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(path, []byte("original"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Chown(path, 4242, 4343); err != nil {
+		t.Fatalf("Failed to change owner: %v", err)
+	}
+	if err := os.Chmod(path, 0644|os.ModeSetgid); err != nil {
+		t.Fatalf("Failed to change mode: %v", err)
+	}
+
+	if _, err := writeFile(path, "", WriteOptions{}, appendRewrite); err != nil {
+		t.Fatalf("writeFile failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	st := info.Sys().(*syscall.Stat_t)
+	if st.Uid != 4242 || st.Gid != 4343 {
+		t.Errorf("Owner = %d:%d, want 4242:4343", st.Uid, st.Gid)
+	}
+	if info.Mode()&os.ModeSetgid == 0 || info.Mode().Perm() != 0644 {
+		t.Errorf("Mode = %v, want setgid and 0644", info.Mode())
+	}
+}
+
+func TestWriteThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "photo.jpg")
+	link := filepath.Join(dir, "link.jpg")
+	if err := os.WriteFile(target, []byte("original"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Symlink("photo.jpg", link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	if _, err := writeFile(link, "", WriteOptions{}, appendRewrite); err != nil {
+		t.Fatalf("writeFile failed: %v", err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Link was replaced: %v, %v", info, err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil || string(data) != "original rewritten" {
+		t.Errorf("Target = %q, %v", data, err)
+	}
+}
+
+func TestWriteNewFileUmask(t *testing.T) {
+	old := syscall.Umask(027)
+	defer syscall.Umask(old)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "photo.jpg")
+	dst := filepath.Join(dir, "copy.jpg")
+	if err := os.WriteFile(src, []byte("original"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := writeFile(src, dst, WriteOptions{}, appendRewrite); err != nil {
+		t.Fatalf("writeFile failed: %v", err)
+	}
+
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("Mode = %v, want 0640", info.Mode())
+	}
+}
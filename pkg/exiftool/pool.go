@@ -0,0 +1,411 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// ErrPoolClosed is returned by Pool methods called after Close.
+var ErrPoolClosed = errors.New("exiftool: pool is closed")
+
+// PoolOptions configures a Pool.
+type PoolOptions struct {
+	// Context is passed to NewWithContext when the pool creates instances
+	// and is used by methods that do not take a context of their own.
+	// Defaults to context.Background().
+	Context context.Context
+
+	// Prewarm is the number of instances created by NewPool up front.
+	// The remaining instances are created lazily when all others are busy.
+	Prewarm int
+}
+
+// Pool manages up to size ExifTool instances and hands them out to calls,
+// so that independent files are processed in parallel. It exposes the same
+// methods as ExifTool and is safe for concurrent use.
+type Pool struct {
+	ctx   context.Context
+	slots chan struct{} // one token per instance that may be in use
+
+	mu     sync.Mutex
+	idle   []*ExifTool
+	closed bool
+	active sync.WaitGroup
+}
+
+// NewPool creates a pool of at most size instances.
+// If size is zero or negative, runtime.GOMAXPROCS(0) is used.
+func NewPool(size int, opts PoolOptions) (*Pool, error) {
+	if size <= 0 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	p := &Pool{
+		ctx:   ctx,
+		slots: make(chan struct{}, size),
+	}
+
+	for i := 0; i < opts.Prewarm && i < size; i++ {
+		et, err := NewWithContext(ctx)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.idle = append(p.idle, et)
+	}
+
+	return p, nil
+}
+
+// get waits for a free slot and returns an idle instance, creating one if
+// none is available.
+func (p *Pool) get(ctx context.Context) (*ExifTool, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.slots
+		return nil, ErrPoolClosed
+	}
+	p.active.Add(1)
+	var et *ExifTool
+	if n := len(p.idle); n > 0 {
+		et = p.idle[n-1]
+		p.idle = p.idle[:n-1]
+	}
+	p.mu.Unlock()
+
+	if et == nil {
+		var err error
+		et, err = NewWithContext(p.ctx)
+		if err != nil {
+			p.active.Done()
+			<-p.slots
+			return nil, err
+		}
+	}
+	return et, nil
+}
+
+// put returns an instance to the pool. Instances that trapped are closed
+// and replaced by a fresh one on a later get.
+func (p *Pool) put(et *ExifTool) {
+	p.mu.Lock()
+	if p.closed || !et.healthy() {
+		et.Close()
+	} else {
+		p.idle = append(p.idle, et)
+	}
+	p.mu.Unlock()
+
+	p.active.Done()
+	<-p.slots
+}
+
+// do runs fn with an instance from the pool.
+func (p *Pool) do(ctx context.Context, fn func(et *ExifTool) error) error {
+	et, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.put(et)
+	return fn(et)
+}
+
+// Close stops handing out instances, waits for in-flight calls to finish
+// and releases all instances.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	p.active.Wait()
+
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	for _, et := range idle {
+		et.Close()
+	}
+	return nil
+}
+
+// Version returns the ExifTool version.
+func (p *Pool) Version() (string, error) {
+	var version string
+	err := p.do(p.ctx, func(et *ExifTool) error {
+		var err error
+		version, err = et.Version()
+		return err
+	})
+	return version, err
+}
+
+// ReadMetadata reads metadata from an image file.
+func (p *Pool) ReadMetadata(filePath string) (map[string]any, error) {
+	var metadata map[string]any
+	err := p.do(p.ctx, func(et *ExifTool) error {
+		var err error
+		metadata, err = et.ReadMetadata(filePath)
+		return err
+	})
+	return metadata, err
+}
+
+// ReadMetadataContext reads metadata from an image file, interrupting the
+// call when ctx is done.
+func (p *Pool) ReadMetadataContext(ctx context.Context, filePath string) (map[string]any, error) {
+	var metadata map[string]any
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		metadata, err = et.ReadMetadataContext(ctx, filePath)
+		return err
+	})
+	return metadata, err
+}
+
+// ReadMetadataWithOptions reads metadata from an image file as configured
+// by opts.
+func (p *Pool) ReadMetadataWithOptions(ctx context.Context, filePath string, opts ReadOptions) (map[string]any, error) {
+	var metadata map[string]any
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		metadata, err = et.ReadMetadataWithOptions(ctx, filePath, opts)
+		return err
+	})
+	return metadata, err
+}
+
+// ReadTags reads the tags of an image file in file order with their groups,
+// IDs and both raw and print-converted values.
+func (p *Pool) ReadTags(ctx context.Context, filePath string, opts ReadOptions) ([]Tag, error) {
+	var tags []Tag
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		tags, err = et.ReadTags(ctx, filePath, opts)
+		return err
+	})
+	return tags, err
+}
+
+// ReadInto reads the tags mapped by the "exif" struct tags of v from an
+// image file into the struct v points to.
+func (p *Pool) ReadInto(ctx context.Context, filePath string, v any) error {
+	return p.do(ctx, func(et *ExifTool) error {
+		return et.ReadInto(ctx, filePath, v)
+	})
+}
+
+// ExtractBinary returns the data of a binary tag such as ThumbnailImage.
+func (p *Pool) ExtractBinary(ctx context.Context, filePath string, tag string) ([]byte, error) {
+	var data []byte
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		data, err = et.ExtractBinary(ctx, filePath, tag)
+		return err
+	})
+	return data, err
+}
+
+// ExtractBinaryTo writes the data of a binary tag to w.
+func (p *Pool) ExtractBinaryTo(ctx context.Context, filePath string, tag string, w io.Writer) error {
+	return p.do(ctx, func(et *ExifTool) error {
+		return et.ExtractBinaryTo(ctx, filePath, tag, w)
+	})
+}
+
+// ReadMetadataFromReader reads metadata from the image data provided by r.
+func (p *Pool) ReadMetadataFromReader(ctx context.Context, r io.Reader) (map[string]any, error) {
+	var metadata map[string]any
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		metadata, err = et.ReadMetadataFromReader(ctx, r)
+		return err
+	})
+	return metadata, err
+}
+
+// ReadMetadataFromReaderWithOptions reads metadata from the image data
+// provided by r as configured by opts.
+func (p *Pool) ReadMetadataFromReaderWithOptions(ctx context.Context, r io.Reader, opts ReadOptions) (map[string]any, error) {
+	var metadata map[string]any
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		metadata, err = et.ReadMetadataFromReaderWithOptions(ctx, r, opts)
+		return err
+	})
+	return metadata, err
+}
+
+// ReadTagsFromReader reads the tags of the image data provided by r in file
+// order with their groups, IDs and both raw and print-converted values.
+func (p *Pool) ReadTagsFromReader(ctx context.Context, r io.Reader, opts ReadOptions) ([]Tag, error) {
+	var tags []Tag
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		tags, err = et.ReadTagsFromReader(ctx, r, opts)
+		return err
+	})
+	return tags, err
+}
+
+// ExtractBinaryFromReader writes the data of a binary tag of the image data
+// provided by r to w.
+func (p *Pool) ExtractBinaryFromReader(ctx context.Context, r io.Reader, tag string, w io.Writer) error {
+	return p.do(ctx, func(et *ExifTool) error {
+		return et.ExtractBinaryFromReader(ctx, r, tag, w)
+	})
+}
+
+// ReadMetadataFromBytes reads metadata from an in-memory image.
+func (p *Pool) ReadMetadataFromBytes(ctx context.Context, data []byte) (map[string]any, error) {
+	var metadata map[string]any
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		metadata, err = et.ReadMetadataFromBytes(ctx, data)
+		return err
+	})
+	return metadata, err
+}
+
+// WriteMetadata writes multiple tags to an image file.
+// If dstPath is empty, the source file is modified in place.
+func (p *Pool) WriteMetadata(srcPath string, dstPath string, tags map[string]any) error {
+	return p.do(p.ctx, func(et *ExifTool) error {
+		return et.WriteMetadata(srcPath, dstPath, tags)
+	})
+}
+
+// WriteMetadataContext writes multiple tags to an image file, interrupting
+// the call when ctx is done.
+// If dstPath is empty, the source file is modified in place.
+func (p *Pool) WriteMetadataContext(ctx context.Context, srcPath string, dstPath string, tags map[string]any) error {
+	return p.do(ctx, func(et *ExifTool) error {
+		return et.WriteMetadataContext(ctx, srcPath, dstPath, tags)
+	})
+}
+
+// WriteMetadataWithOptions writes multiple tags to an image file and
+// reports the outcome.
+// If dstPath is empty, the source file is modified in place.
+func (p *Pool) WriteMetadataWithOptions(ctx context.Context, srcPath string, dstPath string, tags map[string]any, opts WriteOptions) (*WriteResult, error) {
+	var result *WriteResult
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		result, err = et.WriteMetadataWithOptions(ctx, srcPath, dstPath, tags, opts)
+		return err
+	})
+	return result, err
+}
+
+// WriteMetadataTo writes multiple tags to the image read from r and writes
+// the modified image to w.
+func (p *Pool) WriteMetadataTo(ctx context.Context, r io.Reader, w io.Writer, tags map[string]any) error {
+	return p.do(ctx, func(et *ExifTool) error {
+		return et.WriteMetadataTo(ctx, r, w, tags)
+	})
+}
+
+// WriteMetadataToWithOptions writes multiple tags to the image read from r,
+// writes the modified image to w and reports the outcome.
+func (p *Pool) WriteMetadataToWithOptions(ctx context.Context, r io.Reader, w io.Writer, tags map[string]any, opts WriteOptions) (*WriteResult, error) {
+	var result *WriteResult
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		result, err = et.WriteMetadataToWithOptions(ctx, r, w, tags, opts)
+		return err
+	})
+	return result, err
+}
+
+// SetTag writes a single tag to an image file.
+// If dstPath is empty, the source file is modified in place.
+func (p *Pool) SetTag(srcPath string, dstPath string, tag string, value string) error {
+	return p.do(p.ctx, func(et *ExifTool) error {
+		return et.SetTag(srcPath, dstPath, tag, value)
+	})
+}
+
+// DeleteTags deletes tags or groups such as "GPS:all" from an image file.
+// If dstPath is empty, the source file is modified in place.
+func (p *Pool) DeleteTags(ctx context.Context, srcPath string, dstPath string, tags ...string) (*WriteResult, error) {
+	return p.DeleteTagsWithOptions(ctx, srcPath, dstPath, tags, DeleteOptions{})
+}
+
+// DeleteTagsWithOptions deletes tags from an image file as configured by
+// opts.
+func (p *Pool) DeleteTagsWithOptions(ctx context.Context, srcPath string, dstPath string, tags []string, opts DeleteOptions) (*WriteResult, error) {
+	var result *WriteResult
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		result, err = et.DeleteTagsWithOptions(ctx, srcPath, dstPath, tags, opts)
+		return err
+	})
+	return result, err
+}
+
+// DeleteTagsTo deletes tags from the image read from r and writes the
+// modified image to w.
+func (p *Pool) DeleteTagsTo(ctx context.Context, r io.Reader, w io.Writer, tags []string, opts DeleteOptions) (*WriteResult, error) {
+	var result *WriteResult
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		result, err = et.DeleteTagsTo(ctx, r, w, tags, opts)
+		return err
+	})
+	return result, err
+}
+
+// CopyMetadata copies tags from the file at srcMetaPath into the image at
+// targetPath and writes the result to dstPath.
+func (p *Pool) CopyMetadata(ctx context.Context, srcMetaPath string, targetPath string, dstPath string, opts CopyOptions) (*WriteResult, error) {
+	var result *WriteResult
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		result, err = et.CopyMetadata(ctx, srcMetaPath, targetPath, dstPath, opts)
+		return err
+	})
+	return result, err
+}
+
+// CopyMetadataTo copies tags from the file read from meta into the image
+// read from r and writes the modified image to w.
+func (p *Pool) CopyMetadataTo(ctx context.Context, meta io.Reader, r io.Reader, w io.Writer, opts CopyOptions) (*WriteResult, error) {
+	var result *WriteResult
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		result, err = et.CopyMetadataTo(ctx, meta, r, w, opts)
+		return err
+	})
+	return result, err
+}
+
+// Organize moves files to paths computed from their metadata using a single
+// instance for all files.
+func (p *Pool) Organize(ctx context.Context, paths []string, template string, opts OrganizeOptions) ([]Move, error) {
+	var moves []Move
+	err := p.do(ctx, func(et *ExifTool) error {
+		var err error
+		moves, err = et.Organize(ctx, paths, template, opts)
+		return err
+	})
+	return moves, err
+}
@@ -0,0 +1,124 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestPoolReadMetadata(t *testing.T) {
+	pool, err := NewPool(2, PoolOptions{Prewarm: 1})
+	if err != nil {
+		t.Fatalf("Failed to create Pool: %v", err)
+	}
+	defer pool.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			metadata, err := pool.ReadMetadata(srcPath)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if fileType := metadata["FileType"]; fileType != "JPEG" {
+				errs <- errors.New("FileType should be JPEG")
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	pool.mu.Lock()
+	idle := len(pool.idle)
+	pool.mu.Unlock()
+	if idle < 1 || idle > 2 {
+		t.Errorf("Pool should hold between 1 and 2 idle instances, got %d", idle)
+	}
+}
+
+func TestPoolWriteMetadata(t *testing.T) {
+	pool, err := NewPool(2, PoolOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create Pool: %v", err)
+	}
+	defer pool.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+	if err := pool.SetTag(srcPath, dstPath, "Artist", "Pool Artist"); err != nil {
+		t.Fatalf("SetTag failed: %v", err)
+	}
+
+	metadata, err := pool.ReadMetadata(dstPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	if artist := metadata["Artist"]; artist != "Pool Artist" {
+		t.Errorf("Artist tag not set correctly: got %v", artist)
+	}
+}
+
+func TestPoolReplacesTrappedInstance(t *testing.T) {
+	pool, err := NewPool(1, PoolOptions{Prewarm: 1})
+	if err != nil {
+		t.Fatalf("Failed to create Pool: %v", err)
+	}
+	defer pool.Close()
+
+	et, err := pool.get(context.Background())
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	et.mu.Lock()
+	et.trapped = true
+	et.mu.Unlock()
+	pool.put(et)
+
+	pool.mu.Lock()
+	idle := len(pool.idle)
+	pool.mu.Unlock()
+	if idle != 0 {
+		t.Fatalf("Trapped instance should not be returned to the pool")
+	}
+
+	if _, err := pool.Version(); err != nil {
+		t.Errorf("Version should succeed with a replacement instance: %v", err)
+	}
+}
+
+func TestPoolClose(t *testing.T) {
+	pool, err := NewPool(1, PoolOptions{Prewarm: 1})
+	if err != nil {
+		t.Fatalf("Failed to create Pool: %v", err)
+	}
+
+	pool.mu.Lock()
+	tmpDir := pool.idle[0].tmpDir
+	pool.mu.Unlock()
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, err := pool.Version(); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Expected ErrPoolClosed after Close, got %v", err)
+	}
+
+	if _, err := os.Stat(tmpDir); !os.IsNotExist(err) {
+		t.Error("Idle instances should be released on Close")
+	}
+}
@@ -0,0 +1,177 @@
+package exiftool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ReadOptions configures which tags are extracted and how they are reported.
+// The zero value matches the defaults of the exiftool command line. Keys of
+// the returned map are always tag names, as with its -s option.
+type ReadOptions struct {
+	// Tags limits extraction to these tags. Names may include a group and
+	// wildcards, e.g. "EXIF:DateTimeOriginal", "GPS:all" or "*Date".
+	// Empty means all tags.
+	Tags []string
+
+	// Exclude lists tags not to extract, like the -x option.
+	Exclude []string
+
+	// Groups prefixes each key with its group name, like the -G option.
+	// The value is a group family, e.g. "0" or "1", or several families
+	// joined by colons such as "0:1". Empty means no prefix.
+	Groups string
+
+	// Numeric returns raw values without print conversion, like -n.
+	Numeric bool
+
+	// Duplicates keeps tags with duplicate names, like -a. Without a group
+	// prefix, duplicates are reported as "Tag (1)", "Tag (2)" and so on.
+	Duplicates bool
+
+	// Unknown also extracts unknown tags, like -u.
+	Unknown bool
+
+	// Lists returns list-type tags such as Keywords as arrays, like -json,
+	// so items containing commas stay intact. By default the items are
+	// joined by ", ".
+	Lists bool
+
+	// DateFormat is a strftime format for date/time values, like -d.
+	DateFormat string
+
+	// fileTags are the System tags of the caller's file set by the
+	// path-based read functions; see fileTags.
+	fileTags map[string]any
+}
+
+// forFile returns the options with the System tags of the caller's file f
+// at path, so FileName, Directory, FileModifyDate and the like describe it
+// rather than the sandbox copy.
+func (o ReadOptions) forFile(path string, f *os.File) (ReadOptions, error) {
+	tags, err := fileTags(path, f)
+	if err != nil {
+		return o, err
+	}
+	o.fileTags = tags
+	return o, nil
+}
+
+// args returns the arguments of the read routines for the sandbox file.
+func (o ReadOptions) args(file string) map[string]any {
+	return map[string]any{
+		"file":        file,
+		"tags":        o.Tags,
+		"exclude":     o.Exclude,
+		"groups":      o.Groups,
+		"numeric":     o.Numeric,
+		"duplicates":  o.Duplicates,
+		"unknown":     o.Unknown,
+		"lists":       o.Lists,
+		"date_format": o.DateFormat,
+		"file_tags":   o.fileTags,
+	}
+}
+
+// ReadMetadataWithOptions reads metadata from an image file as configured by
+// opts.
+func (et *ExifTool) ReadMetadataWithOptions(ctx context.Context, filePath string, opts ReadOptions) (map[string]any, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	opts, err = opts.forFile(filePath, f)
+	if err != nil {
+		return nil, err
+	}
+	return et.readMetadata(ctx, f, opts)
+}
+
+// ReadMetadataFromReaderWithOptions reads metadata from the image data
+// provided by r as configured by opts. File system tags such as FileName
+// describe the temporary copy in the sandbox.
+func (et *ExifTool) ReadMetadataFromReaderWithOptions(ctx context.Context, r io.Reader, opts ReadOptions) (map[string]any, error) {
+	return et.readMetadata(ctx, r, opts)
+}
+
+// Tag is a single tag as reported by ReadTags.
+type Tag struct {
+	// Name is the tag name without group, e.g. "DateTimeOriginal".
+	Name string `json:"name"`
+	// Description is the human readable tag name, e.g. "Date/Time Original".
+	Description string `json:"description"`
+	// Group0 is the family 0 (general location) group, e.g. "EXIF", "XMP"
+	// or "QuickTime".
+	Group0 string `json:"group0"`
+	// Group1 is the family 1 (specific location) group, e.g. "ExifIFD".
+	Group1 string `json:"group1"`
+	// Group2 is the family 2 (category) group, e.g. "Time" or "Camera".
+	Group2 string `json:"group2"`
+	// ID is the tag ID within its table. Numerical IDs are formatted in hex,
+	// e.g. "0x9003".
+	ID string `json:"id"`
+	// Table is the name of the ExifTool tag table, e.g. "Image::ExifTool::Exif::Main".
+	Table string `json:"table"`
+	// Value is the value without print conversion, like the -n option.
+	Value any `json:"value"`
+	// PrintValue is the print-converted value.
+	PrintValue any `json:"print_value"`
+	// Index distinguishes tags with the same name: 0 for the first one and
+	// 1, 2, ... for duplicates, which are only reported with
+	// ReadOptions.Duplicates.
+	Index int `json:"index"`
+}
+
+// ReadTags reads the tags of an image file in the order they appear in the
+// file, with their groups, IDs and both raw and print-converted values.
+// ReadOptions.Numeric and ReadOptions.Groups have no effect.
+func (et *ExifTool) ReadTags(ctx context.Context, filePath string, opts ReadOptions) ([]Tag, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	opts, err = opts.forFile(filePath, f)
+	if err != nil {
+		return nil, err
+	}
+	return et.readTags(ctx, f, opts)
+}
+
+// ReadTagsFromReader reads the tags of the image data provided by r like
+// ReadTags.
+func (et *ExifTool) ReadTagsFromReader(ctx context.Context, r io.Reader, opts ReadOptions) ([]Tag, error) {
+	return et.readTags(ctx, r, opts)
+}
+
+// readTags stages the image data in the sandbox and extracts its tags.
+func (et *ExifTool) readTags(ctx context.Context, r io.Reader, opts ReadOptions) ([]Tag, error) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	tmpFile, guestFile := et.sandboxFile("input")
+	if err := stageFile(tmpFile, r); err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmpFile)
+
+	var result struct {
+		Tags     []Tag    `json:"tags"`
+		Error    string   `json:"error"`
+		Warnings []string `json:"warnings"`
+	}
+	err := et.call(ctx, "read_tags", opts.args(guestFile), &result)
+	if err != nil {
+		return nil, err
+	}
+	if err := et.exifToolError("read", result.Error, result.Warnings); err != nil {
+		return nil, err
+	}
+
+	return result.Tags, nil
+}
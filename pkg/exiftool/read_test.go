@@ -0,0 +1,201 @@
+package exiftool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadMetadataWithOptionsGolden(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+	goldenPath := filepath.Join("testdata", "read_options_golden.json")
+
+	exposure := []string{"Orientation", "ExposureTime", "Flash", "DateTimeOriginal"}
+	cases := map[string]ReadOptions{
+		"print":       {Tags: exposure},
+		"numeric":     {Tags: exposure, Numeric: true},
+		"groups":      {Tags: []string{"Make", "XResolution"}, Groups: "1", Duplicates: true},
+		"duplicates":  {Tags: []string{"XResolution"}, Duplicates: true},
+		"exclude":     {Tags: []string{"EXIF:Make", "EXIF:Model", "EXIF:Software"}, Exclude: []string{"Software"}},
+		"date_format": {Tags: []string{"DateTimeOriginal", "ModifyDate"}, DateFormat: "%Y-%m-%d %H:%M"},
+	}
+
+	actual := make(map[string]map[string]any)
+	for name, opts := range cases {
+		metadata, err := et.ReadMetadataWithOptions(context.Background(), srcPath, opts)
+		if err != nil {
+			t.Fatalf("%s: ReadMetadataWithOptions failed: %v", name, err)
+		}
+		actual[name] = metadata
+	}
+
+	if *updateGolden {
+		// Update golden file
+		data, err := json.MarshalIndent(actual, "", "  ")
+		if err != nil {
+			t.Fatalf("Failed to marshal golden data: %v", err)
+		}
+		if err := os.WriteFile(goldenPath, data, 0644); err != nil {
+			t.Fatalf("Failed to write golden file: %v", err)
+		}
+		t.Log("Golden file updated")
+		return
+	}
+
+	// Load golden file
+	goldenData, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with -update to create): %v", err)
+	}
+
+	var expected map[string]map[string]any
+	if err := json.Unmarshal(goldenData, &expected); err != nil {
+		t.Fatalf("Failed to unmarshal golden file: %v", err)
+	}
+
+	// Compare values as text, since JSON::PP may encode the same value as a
+	// number or a string depending on how Perl last used it
+	for name := range cases {
+		if fmt.Sprint(actual[name]) != fmt.Sprint(expected[name]) {
+			actualJSON, _ := json.MarshalIndent(actual[name], "", "  ")
+			expectedJSON, _ := json.MarshalIndent(expected[name], "", "  ")
+			t.Errorf("%s: metadata mismatch.\nActual:\n%s\n\nExpected:\n%s", name, actualJSON, expectedJSON)
+		}
+	}
+}
+
+func TestReadMetadataWithOptionsDefaults(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+
+	withOptions, err := et.ReadMetadataWithOptions(context.Background(), srcPath, ReadOptions{})
+	if err != nil {
+		t.Fatalf("ReadMetadataWithOptions failed: %v", err)
+	}
+	plain, err := et.ReadMetadata(srcPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+
+	if len(withOptions) != len(plain) {
+		t.Errorf("Zero ReadOptions should match ReadMetadata: %d vs %d tags", len(withOptions), len(plain))
+	}
+	if _, ok := plain["XResolution (1)"]; ok {
+		t.Error("Duplicates should be dropped by default")
+	}
+}
+
+func TestReadTags(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+
+	tags, err := et.ReadTags(context.Background(), srcPath, ReadOptions{Duplicates: true})
+	if err != nil {
+		t.Fatalf("ReadTags failed: %v", err)
+	}
+
+	find := func(name, group1 string) *Tag {
+		for i := range tags {
+			if tags[i].Name == name && (group1 == "" || tags[i].Group1 == group1) {
+				return &tags[i]
+			}
+		}
+		return nil
+	}
+
+	dto := find("DateTimeOriginal", "")
+	if dto == nil {
+		t.Fatal("DateTimeOriginal should be present")
+	}
+	if dto.Group0 != "EXIF" || dto.Group1 != "ExifIFD" || dto.Group2 != "Time" {
+		t.Errorf("Unexpected groups for DateTimeOriginal: %s/%s/%s", dto.Group0, dto.Group1, dto.Group2)
+	}
+	if dto.ID != "0x9003" {
+		t.Errorf("Expected ID 0x9003, got %q", dto.ID)
+	}
+	if dto.Description != "Date/Time Original" {
+		t.Errorf("Unexpected description: %q", dto.Description)
+	}
+	if dto.Table != "Image::ExifTool::Exif::Main" {
+		t.Errorf("Unexpected table: %q", dto.Table)
+	}
+
+	orientation := find("Orientation", "IFD0")
+	if orientation == nil {
+		t.Fatal("IFD0:Orientation should be present")
+	}
+	if fmt.Sprint(orientation.Value) != "1" || orientation.PrintValue != "Horizontal (normal)" {
+		t.Errorf("Unexpected Orientation values: %v / %v", orientation.Value, orientation.PrintValue)
+	}
+
+	// Duplicates are reported with increasing indexes
+	ifd1 := find("XResolution", "IFD1")
+	if ifd1 == nil || ifd1.Index == 0 {
+		t.Errorf("IFD1:XResolution should be present as a duplicate, got %+v", ifd1)
+	}
+
+	// Tags are in file order: IFD0 comes before the ExifIFD it points to
+	makeIdx, dtoIdx := -1, -1
+	for i, tag := range tags {
+		switch tag.Name {
+		case "Make":
+			makeIdx = i
+		case "DateTimeOriginal":
+			dtoIdx = i
+		}
+	}
+	if makeIdx > dtoIdx {
+		t.Errorf("Make (%d) should come before DateTimeOriginal (%d)", makeIdx, dtoIdx)
+	}
+}
+
+func TestReadFromReaderWithOptions(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "test.jpg"))
+	if err != nil {
+		t.Fatalf("Failed to read test image: %v", err)
+	}
+	ctx := context.Background()
+	opts := ReadOptions{Tags: []string{"Orientation", "Make"}, Groups: "1", Numeric: true}
+
+	metadata, err := et.ReadMetadataFromReaderWithOptions(ctx, bytes.NewReader(data), opts)
+	if err != nil {
+		t.Fatalf("ReadMetadataFromReaderWithOptions failed: %v", err)
+	}
+	if len(metadata) != 2 || fmt.Sprint(metadata["IFD0:Orientation"]) != "1" || metadata["IFD0:Make"] != "Canon" {
+		t.Errorf("Unexpected metadata: %v", metadata)
+	}
+
+	tags, err := et.ReadTagsFromReader(ctx, bytes.NewReader(data), ReadOptions{Tags: []string{"Make"}})
+	if err != nil {
+		t.Fatalf("ReadTagsFromReader failed: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Name != "Make" || tags[0].Group1 != "IFD0" {
+		t.Errorf("Unexpected tags: %+v", tags)
+	}
+}
@@ -0,0 +1,221 @@
+package exiftool
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// wasmPageSize is the size of a WebAssembly memory page.
+const wasmPageSize = 65536
+
+// snapshotMagic identifies snapshot files in the cache directory.
+var snapshotMagic = []byte("ETGOSNP1")
+
+var snapshotsEnabled atomic.Bool
+
+// EnableSnapshots controls whether instances are created from a snapshot of
+// an initialized interpreter. When enabled, the first instance initializes
+// Perl and loads Image::ExifTool as usual and its linear memory is saved;
+// later instances restore that memory instead of initializing again, which
+// reduces startup to a memory copy. If a compilation cache directory is in
+// use, the snapshot is also stored there so that later processes start fast.
+// Snapshots are disabled by default.
+//
+// All restored instances, including those of later processes restored from
+// the stored snapshot, start with the Perl state of the snapshot, so they
+// share one hash seed and one random number state: hash order and the
+// results of rand are the same in each of them.
+func EnableSnapshots(enabled bool) {
+	snapshotsEnabled.Store(enabled)
+}
+
+// loadSnapshot returns the memory image of an initialized instance, reading
+// it from the cache directory if it is not in memory yet. It returns nil if
+// no usable snapshot exists.
+func (e *engine) loadSnapshot() []byte {
+	e.snapshotMu.Lock()
+	defer e.snapshotMu.Unlock()
+
+	if e.snapshotBroken {
+		return nil
+	}
+	if e.snapshot == nil && !e.snapshotRead {
+		e.snapshotRead = true
+		if path := e.snapshotPath(); path != "" {
+			if data, err := os.ReadFile(path); err == nil {
+				e.snapshot, _ = decodeSnapshot(data)
+			}
+		}
+	}
+	return e.snapshot
+}
+
+// storeSnapshot saves a copy of mem as the snapshot unless one exists.
+func (e *engine) storeSnapshot(mem api.Memory) {
+	e.snapshotMu.Lock()
+	defer e.snapshotMu.Unlock()
+
+	if e.snapshot != nil || e.snapshotBroken {
+		return
+	}
+	image, ok := mem.Read(0, mem.Size())
+	if !ok {
+		return
+	}
+	e.snapshot = bytes.Clone(image)
+
+	// Persisting is best effort, a missing file only costs startup time
+	if path := e.snapshotPath(); path != "" {
+		writeSnapshotFile(path, encodeSnapshot(e.snapshot))
+	}
+}
+
+// discardSnapshot stops using snapshots in this engine, e.g. after a restored
+// instance failed its sanity check.
+func (e *engine) discardSnapshot() {
+	e.snapshotMu.Lock()
+	defer e.snapshotMu.Unlock()
+
+	e.snapshot = nil
+	e.snapshotBroken = true
+	if path := e.snapshotPath(); path != "" {
+		os.Remove(path)
+	}
+}
+
+// snapshotPath returns the file used to persist the snapshot, or an empty
+// string if there is no cache directory. The name depends on the wasm binary
+// and the preload script, so a snapshot is never restored into a module it
+// was not taken from.
+func (e *engine) snapshotPath() string {
+	if e.cacheDir == "" {
+		return ""
+	}
+	h := sha256.New()
+	h.Write(e.wasmBytes)
+	h.Write([]byte(perlPreload))
+	return filepath.Join(e.cacheDir, "snapshot-"+hex.EncodeToString(h.Sum(nil)[:16])+".bin")
+}
+
+// restore replaces the memory of a freshly instantiated module with image.
+// Asking the preloaded ExifToolGo package for the ExifTool version verifies
+// that the restored interpreter and modules are usable.
+func (et *ExifTool) restore(ctx context.Context, image []byte) error {
+	mem := et.mod.Memory()
+	pages := uint32(len(image) / wasmPageSize)
+	if current := mem.Size() / wasmPageSize; current < pages {
+		if _, ok := mem.Grow(pages - current); !ok {
+			return errors.New("failed to grow memory")
+		}
+	}
+	if mem.Size() != uint32(len(image)) {
+		return errors.New("snapshot size mismatch")
+	}
+	if !mem.Write(0, image) {
+		return errors.New("failed to write snapshot")
+	}
+
+	// Run a routine of the preloaded ExifTool, not just the interpreter
+	var version string
+	if err := et.call(ctx, "version", map[string]any{}, &version); err != nil {
+		return err
+	}
+	if version == "" {
+		return errors.New("restored instance reports no ExifTool version")
+	}
+	return nil
+}
+
+// encodeSnapshot serializes a memory image, leaving out pages that are
+// entirely zero. The layout is the magic, the page count, a bitmap of the
+// stored pages and the stored pages in order.
+func encodeSnapshot(image []byte) []byte {
+	pages := len(image) / wasmPageSize
+	bitmap := make([]byte, (pages+7)/8)
+	var stored [][]byte
+	zero := make([]byte, wasmPageSize)
+	for i := 0; i < pages; i++ {
+		page := image[i*wasmPageSize : (i+1)*wasmPageSize]
+		if bytes.Equal(page, zero) {
+			continue
+		}
+		bitmap[i/8] |= 1 << (i % 8)
+		stored = append(stored, page)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(snapshotMagic)+4+len(bitmap)+len(stored)*wasmPageSize))
+	buf.Write(snapshotMagic)
+	binary.Write(buf, binary.LittleEndian, uint32(pages))
+	buf.Write(bitmap)
+	for _, page := range stored {
+		buf.Write(page)
+	}
+	return buf.Bytes()
+}
+
+// decodeSnapshot is the inverse of encodeSnapshot.
+func decodeSnapshot(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, snapshotMagic) || len(data) < len(snapshotMagic)+4 {
+		return nil, errors.New("invalid snapshot header")
+	}
+	data = data[len(snapshotMagic):]
+	pages := int(binary.LittleEndian.Uint32(data))
+	data = data[4:]
+
+	bitmapLen := (pages + 7) / 8
+	if len(data) < bitmapLen {
+		return nil, errors.New("truncated snapshot")
+	}
+	bitmap, data := data[:bitmapLen], data[bitmapLen:]
+
+	image := make([]byte, pages*wasmPageSize)
+	for i := 0; i < pages; i++ {
+		if bitmap[i/8]&(1<<(i%8)) == 0 {
+			continue
+		}
+		if len(data) < wasmPageSize {
+			return nil, errors.New("truncated snapshot")
+		}
+		copy(image[i*wasmPageSize:], data[:wasmPageSize])
+		data = data[wasmPageSize:]
+	}
+	if len(data) != 0 {
+		return nil, errors.New("trailing data in snapshot")
+	}
+	return image, nil
+}
+
+// writeSnapshotFile atomically writes data to path, so concurrent processes
+// never read a partially written snapshot.
+func writeSnapshotFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "snapshot-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package exiftool
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotEncoding(t *testing.T) {
+	image := make([]byte, 4*wasmPageSize)
+	copy(image, "head")
+	copy(image[2*wasmPageSize+10:], "middle")
+	image[len(image)-1] = 0xff
+
+	data := encodeSnapshot(image)
+	if len(data) >= len(image) {
+		t.Errorf("Zero pages should not be stored: %d bytes for %d byte image", len(data), len(image))
+	}
+
+	decoded, err := decodeSnapshot(data)
+	if err != nil {
+		t.Fatalf("decodeSnapshot failed: %v", err)
+	}
+	if !bytes.Equal(decoded, image) {
+		t.Error("Decoded image does not match the original")
+	}
+
+	if _, err := decodeSnapshot(data[:len(data)-1]); err == nil {
+		t.Error("decodeSnapshot should fail for truncated data")
+	}
+	if _, err := decodeSnapshot([]byte("garbage")); err == nil {
+		t.Error("decodeSnapshot should fail for invalid header")
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	EnableSnapshots(true)
+	defer EnableSnapshots(false)
+
+	ctx := context.Background()
+	eng, err := newEngine(ctx, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.close(ctx)
+
+	// The first instance initializes Perl and takes the snapshot
+	first, err := newInstance(ctx, eng)
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer first.Close()
+
+	if eng.loadSnapshot() == nil {
+		t.Fatal("Snapshot should be taken after the first instance")
+	}
+	if _, err := os.Stat(eng.snapshotPath()); err != nil {
+		t.Errorf("Snapshot should be stored in the cache directory: %v", err)
+	}
+
+	// The second instance is restored from it
+	second, err := newInstance(ctx, eng)
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool from snapshot: %v", err)
+	}
+	defer second.Close()
+
+	metadata, err := second.ReadMetadata(filepath.Join("testdata", "test.jpg"))
+	if err != nil {
+		t.Fatalf("ReadMetadata failed on restored instance: %v", err)
+	}
+	if fileType := metadata["FileType"]; fileType != "JPEG" {
+		t.Errorf("FileType should be JPEG, got %v", fileType)
+	}
+}
+
+// BenchmarkNewSnapshot measures New when instances are restored from a
+// snapshot instead of initializing Perl.
+func BenchmarkNewSnapshot(b *testing.B) {
+	EnableSnapshots(true)
+	defer EnableSnapshots(false)
+
+	// Make sure the snapshot exists before timing
+	et, err := New()
+	if err != nil {
+		b.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	et.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		et, err := New()
+		if err != nil {
+			b.Fatalf("Failed to create ExifTool: %v", err)
+		}
+		et.Close()
+	}
+}
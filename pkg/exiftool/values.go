@@ -0,0 +1,310 @@
+package exiftool
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// fileRefKey marks a JSON object that refers to a sandbox file holding a
+// value JSON cannot carry byte for byte. It must match $FILE_REF in
+// exiftoolgo.pl.
+const fileRefKey = "exiftoolgo:file"
+
+// binaryRefKey marks a JSON object that refers to a sandbox file holding
+// binary data, which is passed to ExifTool as a scalar reference. It must
+// match $BINARY_REF in exiftoolgo.pl.
+const binaryRefKey = "exiftoolgo:binary"
+
+// editKey marks a JSON object holding a ListEdit or ReplaceValue. It must
+// match $EDIT in exiftoolgo.pl.
+const editKey = "exiftoolgo:edit"
+
+// ListEdit is a tag value that adds and removes items of a list-type tag
+// such as Keywords or Subject instead of replacing the whole list, like
+// -Keywords+=foo and -Keywords-=bar on the command line:
+//
+//	tags := map[string]any{
+//		"Keywords": exiftool.ListEdit{Add: []string{"foo"}, Remove: []string{"bar"}},
+//	}
+//
+// Items are removed before the new ones are added.
+type ListEdit struct {
+	// Add lists the items to add.
+	Add []string
+	// Remove lists the items to remove.
+	Remove []string
+}
+
+// ReplaceValue is a tag value written only where the current value equals
+// Old, like -TAG-=OLD -TAG=NEW on the command line:
+//
+//	tags := map[string]any{"Make": exiftool.Replace("SAMSUNG ", "Samsung")}
+//
+// See Replace.
+type ReplaceValue struct {
+	Old any
+	New any
+}
+
+// Replace returns a value that replaces a tag only if its current value is
+// old. Other files are still rewritten, without changes to the tag.
+func Replace(old, new any) ReplaceValue {
+	return ReplaceValue{Old: old, New: new}
+}
+
+// RawValue is a tag value written without print conversion, like the "#"
+// suffix on the command line. See Raw.
+type RawValue struct {
+	Value any
+}
+
+// Raw marks a value to be written without print conversion, e.g.
+// Raw(6) for Orientation instead of "Rotate 90 CW".
+func Raw(value any) RawValue {
+	return RawValue{Value: value}
+}
+
+// exifDateLayout is the EXIF date/time format.
+const exifDateLayout = "2006:01:02 15:04:05"
+
+// expandTags applies the conversions of write values that depend on the
+// tag: Raw values are written to "TAG#", EXIF date/time tags given as
+// time.Time also set their OffsetTime tag, and GPS coordinates and
+// altitudes given as signed numbers are written as absolute values with
+// their Ref tags. Tags given explicitly take precedence over derived ones.
+func expandTags(tags map[string]any) map[string]any {
+	out := make(map[string]any, len(tags))
+	derived := make(map[string]any)
+	for tag, v := range tags {
+		raw, isRaw := v.(RawValue)
+		if isRaw {
+			tag, v = strings.TrimSuffix(tag, "#")+"#", raw.Value
+		}
+
+		group, name := "", strings.TrimSuffix(tag, "#")
+		if i := strings.LastIndex(name, ":"); i >= 0 {
+			group, name = name[:i], name[i+1:]
+		}
+		prefix := ""
+		if group != "" {
+			prefix = group + ":"
+		}
+
+		if t, ok := v.(time.Time); ok {
+			if offsetTag := offsetTags[name]; offsetTag != "" && isGroup(group, "EXIF", "ExifIFD", "IFD0") {
+				derived[prefix+offsetTag] = t.Format("-07:00")
+			}
+			// Raw date/time values can't carry a time zone
+			if isRaw {
+				v = t.Format(exifDateLayout)
+			}
+		}
+		if ref, ok := gpsRefs[name]; ok && isGroup(group, "EXIF", "GPS") {
+			if f, ok := toFloat(v); ok {
+				derived[prefix+ref.tag] = ref.positive
+				if f < 0 {
+					derived[prefix+ref.tag] = ref.negative
+				}
+				v = math.Abs(f)
+			}
+		}
+		out[tag] = v
+	}
+	for tag, v := range derived {
+		if !hasTag(out, tag) {
+			out[tag] = v
+		}
+	}
+	return out
+}
+
+// gpsRefs maps the GPS tags written from signed numbers to the Ref tags
+// holding their sign.
+var gpsRefs = map[string]struct{ tag, positive, negative string }{
+	"GPSLatitude":  {"GPSLatitudeRef", "North", "South"},
+	"GPSLongitude": {"GPSLongitudeRef", "East", "West"},
+	"GPSAltitude":  {"GPSAltitudeRef", "Above Sea Level", "Below Sea Level"},
+}
+
+// isGroup reports whether group is empty or one of groups.
+func isGroup(group string, groups ...string) bool {
+	if group == "" {
+		return true
+	}
+	for _, g := range groups {
+		if strings.EqualFold(group, g) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag reports whether tags sets the tag, in any group and with or
+// without "#" suffix.
+func hasTag(tags map[string]any, tag string) bool {
+	name := tag[strings.LastIndex(tag, ":")+1:]
+	for t := range tags {
+		t = strings.TrimSuffix(t, "#")
+		if strings.EqualFold(t[strings.LastIndex(t, ":")+1:], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// toFloat returns v as a float if it is a number.
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// valueEncoder converts tag values into the JSON shape understood by the
+// Perl side. Values that cannot be represented in JSON, such as strings that
+// are not valid UTF-8, and binary data given as []byte or io.Reader are
+// staged as files in the sandbox.
+// The caller must hold et.mu until cleanup has been called.
+type valueEncoder struct {
+	et    *ExifTool
+	files []string
+}
+
+// encode returns the JSON-ready form of v.
+func (e *valueEncoder) encode(v any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		if !utf8.ValidString(v) {
+			return e.stage(fileRefKey, bytes.NewReader([]byte(v)))
+		}
+		return v, nil
+	case []byte:
+		return e.stage(binaryRefKey, bytes.NewReader(v))
+	case io.Reader:
+		return e.stage(binaryRefKey, v)
+	case RawValue:
+		return e.encode(v.Value)
+	case time.Time:
+		return v.Format(exifDateLayout + "-07:00"), nil
+	case *big.Rat:
+		if v == nil {
+			return nil, nil
+		}
+		return v.RatString(), nil
+	case big.Rat:
+		return v.RatString(), nil
+	case float64:
+		return formatFloat(v, 64)
+	case float32:
+		return formatFloat(float64(v), 32)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		// Strings keep integers beyond 2^53 exact in Perl
+		return fmt.Sprint(v), nil
+	case ListEdit:
+		add, err := e.encode(v.Add)
+		if err != nil {
+			return nil, err
+		}
+		remove, err := e.encode(v.Remove)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{editKey: map[string]any{"add": add, "remove": remove}}, nil
+	case ReplaceValue:
+		old, err := e.encode(v.Old)
+		if err != nil {
+			return nil, err
+		}
+		value, err := e.encode(v.New)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{editKey: map[string]any{"remove": []any{old}, "value": value}}, nil
+	case []string:
+		out := make([]any, len(v))
+		for i, s := range v {
+			enc, err := e.encode(s)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = enc
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			enc, err := e.encode(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = enc
+		}
+		return out, nil
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			enc, err := e.encode(item)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = enc
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// formatFloat formats f with the fewest digits that represent it exactly,
+// so that Perl doesn't round it.
+func formatFloat(f float64, bitSize int) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("invalid number %v", f)
+	}
+	return strconv.FormatFloat(f, 'f', -1, bitSize), nil
+}
+
+// stage copies r to a new sandbox file and returns a reference to it of the
+// given kind.
+func (e *valueEncoder) stage(key string, r io.Reader) (any, error) {
+	guestPath, err := e.file(r)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{key: guestPath}, nil
+}
+
+// file copies r to a new sandbox file and returns its guest path.
+func (e *valueEncoder) file(r io.Reader) (string, error) {
+	hostPath, guestPath := e.et.sandboxFile("value")
+	e.files = append(e.files, hostPath)
+	if err := stageFile(hostPath, r); err != nil {
+		return "", err
+	}
+	return guestPath, nil
+}
+
+// cleanup removes the files staged by the encoder.
+func (e *valueEncoder) cleanup() {
+	for _, f := range e.files {
+		os.Remove(f)
+	}
+	e.files = nil
+}
@@ -0,0 +1,176 @@
+package exiftool
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValueEncoder(t *testing.T) {
+	et := &ExifTool{tmpDir: t.TempDir()}
+	enc := &valueEncoder{et: et}
+
+	tags := map[string]any{
+		"Artist":         "O'Brien",
+		"Comment":        "caf\xe9",
+		"Keywords":       []string{"valid", "in\xffvalid"},
+		"Rating":         5,
+		"ThumbnailImage": []byte{0xff, 0xd8, 0x00, 0xff, 0xd9},
+		"ICC_Profile":    strings.NewReader("profile"),
+		"Subject":        ListEdit{Add: []string{"add"}, Remove: []string{"remove"}},
+	}
+
+	encoded, err := enc.encode(tags)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "\\ufffd") {
+		t.Errorf("Invalid UTF-8 must not be replaced: %s", data)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if decoded["Artist"] != "O'Brien" {
+		t.Errorf("Valid strings should be passed as is, got %v", decoded["Artist"])
+	}
+	if decoded["Rating"] != "5" {
+		t.Errorf("Numbers should be passed as exact strings, got %v", decoded["Rating"])
+	}
+
+	// Invalid UTF-8 is staged as a file with the exact bytes
+	ref, ok := decoded["Comment"].(map[string]any)
+	if !ok {
+		t.Fatalf("Comment should be a file reference, got %v", decoded["Comment"])
+	}
+	guestPath, _ := ref[fileRefKey].(string)
+	content, err := os.ReadFile(et.tmpDir + strings.TrimPrefix(guestPath, "/tmp"))
+	if err != nil {
+		t.Fatalf("Failed to read staged value: %v", err)
+	}
+	if string(content) != "caf\xe9" {
+		t.Errorf("Staged value mismatch: %q", content)
+	}
+
+	keywords, _ := decoded["Keywords"].([]any)
+	if len(keywords) != 2 || keywords[0] != "valid" {
+		t.Fatalf("Keywords should stay a list, got %v", decoded["Keywords"])
+	}
+	if _, ok := keywords[1].(map[string]any); !ok {
+		t.Errorf("Invalid list items should be file references, got %v", keywords[1])
+	}
+
+	subject, _ := decoded["Subject"].(map[string]any)
+	edit, _ := subject[editKey].(map[string]any)
+	if add, _ := edit["add"].([]any); len(add) != 1 || add[0] != "add" {
+		t.Errorf("ListEdit should be encoded with its items, got %v", decoded["Subject"])
+	}
+	if remove, _ := edit["remove"].([]any); len(remove) != 1 || remove[0] != "remove" {
+		t.Errorf("ListEdit should be encoded with its items, got %v", decoded["Subject"])
+	}
+
+	// Binary data is staged as a file and passed as a scalar reference
+	for tag, want := range map[string]string{"ThumbnailImage": "\xff\xd8\x00\xff\xd9", "ICC_Profile": "profile"} {
+		ref, ok := decoded[tag].(map[string]any)
+		if !ok {
+			t.Fatalf("%s should be a binary reference, got %v", tag, decoded[tag])
+		}
+		guestPath, _ := ref[binaryRefKey].(string)
+		content, err := os.ReadFile(et.tmpDir + strings.TrimPrefix(guestPath, "/tmp"))
+		if err != nil {
+			t.Fatalf("Failed to read staged %s: %v", tag, err)
+		}
+		if string(content) != want {
+			t.Errorf("Staged %s mismatch: %q", tag, content)
+		}
+	}
+
+	enc.cleanup()
+	entries, _ := os.ReadDir(et.tmpDir)
+	if len(entries) != 0 {
+		t.Errorf("cleanup should remove staged files, %d left", len(entries))
+	}
+}
+
+func TestValueEncoderTypes(t *testing.T) {
+	enc := &valueEncoder{et: &ExifTool{tmpDir: t.TempDir()}}
+	defer enc.cleanup()
+
+	tests := []struct {
+		value any
+		want  any
+	}{
+		{time.Date(2008, 5, 30, 15, 56, 1, 0, time.FixedZone("", 9*3600)), "2008:05:30 15:56:01+09:00"},
+		{big.NewRat(1, 160), "1/160"},
+		{big.NewRat(4, 1), "4"},
+		{*big.NewRat(1, 250), "1/250"},
+		{0.1, "0.1"},
+		{float32(7.1), "7.1"},
+		{int64(1) << 60, "1152921504606846976"},
+		{uint16(100), "100"},
+		{Raw(6), "6"},
+	}
+	for _, tt := range tests {
+		got, err := enc.encode(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("encode(%v) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+
+	if _, err := enc.encode(math.NaN()); err == nil {
+		t.Error("encode(NaN) succeeded, want error")
+	}
+}
+
+func TestExpandTags(t *testing.T) {
+	taken := time.Date(2008, 5, 30, 15, 56, 1, 0, time.FixedZone("", -5*3600))
+
+	got := expandTags(map[string]any{
+		"DateTimeOriginal":      taken,
+		"XMP:DateCreated":       taken,
+		"EXIF:ModifyDate":       taken,
+		"EXIF:OffsetTime":       "+01:00",
+		"CreateDate":            Raw(taken),
+		"GPSLatitude":           -33.8568,
+		"GPSLongitude":          151.2153,
+		"GPSAltitude":           -12,
+		"XMP:GPSLatitude":       -33.8568,
+		"EXIF:GPSLongitude":     Raw(-0.5),
+		"EXIF:GPSLongitudeRef#": "W",
+		"Orientation":           Raw(6),
+	})
+
+	want := map[string]any{
+		"DateTimeOriginal":      taken,
+		"OffsetTimeOriginal":    "-05:00",
+		"XMP:DateCreated":       taken,
+		"EXIF:ModifyDate":       taken,
+		"EXIF:OffsetTime":       "+01:00",
+		"CreateDate#":           "2008:05:30 15:56:01",
+		"OffsetTimeDigitized":   "-05:00",
+		"GPSLatitude":           33.8568,
+		"GPSLatitudeRef":        "South",
+		"GPSLongitude":          151.2153,
+		"GPSAltitude":           12.0,
+		"GPSAltitudeRef":        "Below Sea Level",
+		"XMP:GPSLatitude":       -33.8568,
+		"EXIF:GPSLongitude#":    0.5,
+		"EXIF:GPSLongitudeRef#": "W",
+		"Orientation#":          6,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandTags =\n%#v\nwant\n%#v", got, want)
+	}
+}
@@ -0,0 +1,400 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTagRejected is returned by writes with WriteOptions.Strict set when
+// ExifTool refused to set one of the requested tags.
+var ErrTagRejected = errors.New("exiftool: tag rejected")
+
+// ErrConditionFailed is returned by writes whose WriteOptions.Condition is
+// false for the source file. Nothing is written.
+var ErrConditionFailed = errors.New("exiftool: condition not met")
+
+// WriteMode restricts which tags a write may create or edit, like the -wm
+// option. It is a combination of the letters "w" (write existing tags),
+// "c" (create new tags) and "g" (create new groups).
+type WriteMode string
+
+const (
+	// WriteModeDefault writes existing tags and creates new tags and
+	// groups, like -wm wcg.
+	WriteModeDefault WriteMode = ""
+	// WriteModeEditOnly only edits tags that already exist, like -wm w.
+	WriteModeEditOnly WriteMode = "w"
+	// WriteModeCreateOnly only creates tags that don't exist yet, e.g. to
+	// set Copyright only where it is missing, like -wm cg.
+	WriteModeCreateOnly WriteMode = "cg"
+	// WriteModeNoNewGroups edits and creates tags but doesn't create new
+	// groups such as an XMP or IPTC segment, like -wm wc.
+	WriteModeNoNewGroups WriteMode = "wc"
+)
+
+// WriteOptions configures a write.
+type WriteOptions struct {
+	// Strict makes any rejected tag an error. The file is not written and
+	// the returned error wraps ErrTagRejected.
+	Strict bool
+
+	// Mode restricts which tags may be created or edited.
+	Mode WriteMode
+
+	// PreserveModTime keeps the modification time of the source file on the
+	// written file, like the -P option.
+	PreserveModTime bool
+
+	// Backup keeps the previous destination file as FILE_original, like
+	// ExifTool does without -overwrite_original. An existing backup is not
+	// replaced, and no backup is made if a file written in place is
+	// unchanged.
+	Backup bool
+
+	// Condition is a Perl expression evaluated against the metadata of the
+	// source file before it is rewritten, like the -if option, e.g.
+	// `$Make eq "SAMSUNG " and not $Copyright`. Tag names may include a
+	// group and a "#" suffix for the value without print conversion. If
+	// it is false, nothing is written and the error is ErrConditionFailed.
+	Condition string
+}
+
+// args returns the arguments of the write routines for the options.
+func (o WriteOptions) args() map[string]any {
+	return map[string]any{
+		"strict":    o.Strict,
+		"mode":      string(o.Mode),
+		"condition": o.Condition,
+	}
+}
+
+// TagError describes a tag ExifTool refused to set.
+type TagError struct {
+	// Tag is the tag name as requested.
+	Tag string
+	// Reason is the message from ExifTool, e.g. "Tag 'Artsit' is not defined".
+	Reason string
+}
+
+func (e TagError) Error() string {
+	return e.Tag + ": " + e.Reason
+}
+
+// WriteResult describes the outcome of a write.
+type WriteResult struct {
+	// Set lists the requested tags ExifTool accepted, in sorted order.
+	Set []string
+	// Rejected lists the requested tags ExifTool refused to set, such as
+	// unknown or unwritable tags and values that could not be converted.
+	Rejected []TagError
+	// Warnings are the warnings ExifTool reported while writing.
+	Warnings []string
+	// Changed reports whether the written file differs from the source.
+	// ExifTool still writes the file when nothing changed.
+	Changed bool
+}
+
+// WriteMetadataWithOptions writes multiple tags to an image file and reports
+// which tags were set, which were rejected and whether the file changed.
+// If dstPath is empty, the source file is modified in place.
+func (et *ExifTool) WriteMetadataWithOptions(ctx context.Context, srcPath string, dstPath string, tags map[string]any, opts WriteOptions) (*WriteResult, error) {
+	return writeFile(srcPath, dstPath, opts, func(r io.Reader, w io.Writer) (*WriteResult, error) {
+		return et.writeMetadata(ctx, r, w, tags, opts)
+	})
+}
+
+// WriteMetadataToWithOptions writes multiple tags to the image read from r,
+// writes the modified image to w and reports the outcome like
+// WriteMetadataWithOptions. Nothing is written to w if the write fails.
+func (et *ExifTool) WriteMetadataToWithOptions(ctx context.Context, r io.Reader, w io.Writer, tags map[string]any, opts WriteOptions) (*WriteResult, error) {
+	return et.writeMetadata(ctx, r, w, tags, opts)
+}
+
+// writeFile rewrites the file at srcPath and stores the result at dstPath,
+// or in place if dstPath is empty. The output goes to a temporary file in
+// the destination directory that is synced and renamed over the destination,
+// so readers never see a partially written file and a failed write leaves
+// the destination untouched. A symlinked destination is written through, and
+// an existing destination keeps its mode and, where permitted, its owner. A
+// new destination is created with mode 0644 less the umask.
+func writeFile(srcPath string, dstPath string, opts WriteOptions, rewrite func(r io.Reader, w io.Writer) (*WriteResult, error)) (*WriteResult, error) {
+	// Read source file
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+	defer src.Close()
+
+	srcInfo, err := src.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	// Determine destination path
+	dest := dstPath
+	if dest == "" {
+		dest = srcPath
+	}
+
+	// Write through symlinks instead of replacing them
+	if resolved, err := filepath.EvalSymlinks(dest); err == nil {
+		dest = resolved
+	}
+
+	// An existing destination keeps its permissions and owner
+	destInfo, err := os.Stat(dest)
+	if err != nil {
+		destInfo = nil
+	}
+
+	tmp, err := createTemp(dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	result, err := rewrite(src, tmp)
+	if err != nil {
+		return result, err
+	}
+	// Like ExifTool, leave a file that is rewritten in place untouched if
+	// nothing changed, without a backup or a new modification time
+	if result != nil && !result.Changed && destInfo != nil && os.SameFile(srcInfo, destInfo) {
+		return result, nil
+	}
+	// Changing the owner clears the setuid and setgid bits, so chmod last
+	if destInfo != nil {
+		chownLike(tmp, destInfo)
+		mode := destInfo.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+		if err := tmp.Chmod(mode); err != nil {
+			return result, fmt.Errorf("failed to write destination file: %w", err)
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		return result, fmt.Errorf("failed to write destination file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return result, fmt.Errorf("failed to write destination file: %w", err)
+	}
+
+	if opts.PreserveModTime {
+		if err := os.Chtimes(tmpPath, time.Time{}, srcInfo.ModTime()); err != nil {
+			return result, fmt.Errorf("failed to preserve modification time: %w", err)
+		}
+	}
+
+	// Some platforms can't replace a file that is still open
+	src.Close()
+
+	if opts.Backup && destInfo != nil {
+		if err := backupFile(dest); err != nil {
+			return result, err
+		}
+	}
+
+	if err := os.Rename(tmpPath, dest); err != nil {
+		return result, fmt.Errorf("failed to write destination file: %w", err)
+	}
+	committed = true
+	syncDir(filepath.Dir(dest))
+
+	return result, nil
+}
+
+// createTemp creates a new temporary file next to path. Unlike
+// os.CreateTemp, it is created with mode 0644 less the umask, the mode a new
+// destination file gets.
+func createTemp(path string) (*os.File, error) {
+	dir, base := filepath.Split(path)
+	for range 10000 {
+		name := filepath.Join(dir, "."+base+"."+strconv.FormatUint(uint64(rand.Uint32()), 10)+".tmp")
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+		if !os.IsExist(err) {
+			return f, err
+		}
+	}
+	return nil, &os.PathError{Op: "createtemp", Path: path, Err: os.ErrExist}
+}
+
+// backupFile keeps a copy of path as path+"_original", like ExifTool does
+// without -overwrite_original. An existing backup is kept, so it always
+// holds the file from before the first write.
+func backupFile(path string) error {
+	backup := path + "_original"
+	if _, err := os.Lstat(backup); err == nil {
+		return nil
+	}
+
+	// A hard link is cheap and keeps the mode and times
+	if err := os.Link(path, backup); err == nil {
+		return nil
+	}
+	if err := copyFile(path, backup); err != nil {
+		return fmt.Errorf("failed to back up file: %w", err)
+	}
+	return nil
+}
+
+// copyFile copies the file at src to the new file dst, keeping its mode and
+// modification time. dst must not exist.
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Chtimes(dst, time.Time{}, info.ModTime())
+}
+
+// syncDir flushes a directory so that a rename in it is durable. Not all
+// platforms support this, so errors are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// writeMetadata applies the tags to the image read from r and copies the
+// rewritten image to w.
+func (et *ExifTool) writeMetadata(ctx context.Context, r io.Reader, w io.Writer, tags map[string]any, opts WriteOptions) (*WriteResult, error) {
+	return et.rewrite(ctx, r, w, "write_metadata", opts, func(enc *valueEncoder) (map[string]any, error) {
+		// Convert tag values, staging those JSON can't carry as files
+		encodedTags, err := enc.encode(expandTags(tags))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode tags: %w", err)
+		}
+		return map[string]any{
+			"tags": encodedTags,
+		}, nil
+	})
+}
+
+// rewrite stages the image data in the sandbox, calls a Perl routine that
+// writes a new image from it as configured by opts and copies the rewritten
+// image to w. args returns the arguments of the routine besides src, dst and
+// the write options; it runs with the lock held so it can stage values with
+// the encoder. The result is
+// returned along with ErrTagRejected errors so callers can see what was
+// rejected.
+func (et *ExifTool) rewrite(ctx context.Context, r io.Reader, w io.Writer, routine string, opts WriteOptions, args func(enc *valueEncoder) (map[string]any, error)) (*WriteResult, error) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	// Write to temp input file
+	tmpInput, guestInput := et.sandboxFile("input")
+	if err := stageFile(tmpInput, r); err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmpInput)
+
+	tmpOutput, guestOutput := et.sandboxFile("output")
+	defer os.Remove(tmpOutput)
+
+	enc := &valueEncoder{et: et}
+	defer enc.cleanup()
+	params, err := args(enc)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range opts.args() {
+		params[k] = v
+	}
+	params["src"] = guestInput
+	params["dst"] = guestOutput
+
+	// Write metadata
+	var resp struct {
+		Status   int      `json:"status"`
+		Error    string   `json:"error"`
+		Set      []string `json:"set"`
+		Rejected []struct {
+			Tag    string `json:"tag"`
+			Reason string `json:"reason"`
+		} `json:"rejected"`
+		Warnings []string `json:"warnings"`
+		Aborted  bool     `json:"aborted"`
+		Skipped  bool     `json:"skipped"`
+	}
+	err = et.call(ctx, routine, params, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute write: %w", err)
+	}
+
+	result := &WriteResult{
+		Set:      resp.Set,
+		Warnings: resp.Warnings,
+		Changed:  resp.Status == 1,
+	}
+	for _, rej := range resp.Rejected {
+		result.Rejected = append(result.Rejected, TagError{Tag: rej.Tag, Reason: rej.Reason})
+	}
+
+	// Strict mode stops before WriteInfo if any tag was rejected
+	if resp.Aborted {
+		reasons := make([]string, len(result.Rejected))
+		for i, rej := range result.Rejected {
+			reasons[i] = rej.Error()
+		}
+		return result, fmt.Errorf("%w: %s", ErrTagRejected, strings.Join(reasons, "; "))
+	}
+
+	// The condition was false for the source file
+	if resp.Skipped {
+		return result, ErrConditionFailed
+	}
+
+	// Check result: 1=written, 2=written without changes, 0=failure
+	if resp.Status == 0 {
+		if resp.Error == "" {
+			resp.Error = "write failed"
+		}
+		return result, et.exifToolError("write", resp.Error, resp.Warnings)
+	}
+
+	// Read output file
+	f, err := os.Open(tmpOutput)
+	if err != nil {
+		return result, fmt.Errorf("failed to read output file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(w, f); err != nil {
+		return result, fmt.Errorf("failed to copy output: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,475 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWriteMetadataWithOptionsResult(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+	tags := map[string]any{
+		"Artist": "Result Artist",
+		"Artsit": "Typo",
+	}
+
+	result, err := et.WriteMetadataWithOptions(context.Background(), srcPath, dstPath, tags, WriteOptions{})
+	if err != nil {
+		t.Fatalf("WriteMetadataWithOptions failed: %v", err)
+	}
+
+	if !slices.Equal(result.Set, []string{"Artist"}) {
+		t.Errorf("Expected Set [Artist], got %v", result.Set)
+	}
+	if len(result.Rejected) != 1 || result.Rejected[0].Tag != "Artsit" || result.Rejected[0].Reason == "" {
+		t.Errorf("Expected Artsit to be rejected with a reason, got %v", result.Rejected)
+	}
+	if !result.Changed {
+		t.Error("File should be reported as changed")
+	}
+}
+
+func TestWriteMetadataWithOptionsUnchanged(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+	result, err := et.WriteMetadataWithOptions(context.Background(), srcPath, dstPath, map[string]any{
+		"Artsit": "Typo",
+	}, WriteOptions{})
+	if err != nil {
+		t.Fatalf("WriteMetadataWithOptions failed: %v", err)
+	}
+
+	if len(result.Set) != 0 {
+		t.Errorf("No tag should be set, got %v", result.Set)
+	}
+	if result.Changed {
+		t.Error("File should not be reported as changed")
+	}
+}
+
+func TestWriteMetadataInPlaceUnchanged(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	mtime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)
+	path := copyTestImage(t, mtime)
+
+	result, err := et.WriteMetadataWithOptions(context.Background(), path, "", map[string]any{
+		"Artsit": "Typo",
+	}, WriteOptions{Backup: true})
+	if err != nil {
+		t.Fatalf("WriteMetadataWithOptions failed: %v", err)
+	}
+	if result.Changed {
+		t.Error("File should not be reported as changed")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("ModTime = %v, want %v", info.ModTime(), mtime)
+	}
+	if _, err := os.Stat(path + "_original"); !os.IsNotExist(err) {
+		t.Errorf("No backup should be made, got %v", err)
+	}
+}
+
+func TestWriteMetadataWithOptionsStrict(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+	tags := map[string]any{
+		"Artist": "Strict Artist",
+		"Artsit": "Typo",
+	}
+
+	result, err := et.WriteMetadataWithOptions(context.Background(), srcPath, dstPath, tags, WriteOptions{Strict: true})
+	if !errors.Is(err, ErrTagRejected) {
+		t.Fatalf("Expected ErrTagRejected, got %v", err)
+	}
+	if result == nil || len(result.Rejected) != 1 {
+		t.Errorf("Result should list the rejected tag, got %+v", result)
+	}
+
+	if _, err := os.Stat(dstPath); !os.IsNotExist(err) {
+		t.Error("Destination should not be written in strict mode")
+	}
+}
+
+func TestWriteListValues(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx := context.Background()
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dir := t.TempDir()
+	listPath := filepath.Join(dir, "list.jpg")
+	editPath := filepath.Join(dir, "edit.jpg")
+
+	err = et.WriteMetadata(srcPath, listPath, map[string]any{
+		"Keywords": []string{"sunset", "beach, sand", "sea"},
+	})
+	if err != nil {
+		t.Fatalf("WriteMetadata failed: %v", err)
+	}
+
+	metadata, err := et.ReadMetadata(listPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	if metadata["Keywords"] != "sunset, beach, sand, sea" {
+		t.Errorf("Keywords = %v, want the joined items", metadata["Keywords"])
+	}
+
+	lists := ReadOptions{Lists: true}
+	metadata, err = et.ReadMetadataWithOptions(ctx, listPath, lists)
+	if err != nil {
+		t.Fatalf("ReadMetadataWithOptions failed: %v", err)
+	}
+	keywords, err := Metadata(metadata).Strings("Keywords")
+	if err != nil || !slices.Equal(keywords, []string{"sunset", "beach, sand", "sea"}) {
+		t.Fatalf("Keywords = %v, %v", keywords, err)
+	}
+
+	result, err := et.WriteMetadataWithOptions(ctx, listPath, editPath, map[string]any{
+		"Keywords": ListEdit{Add: []string{"holiday"}, Remove: []string{"sea"}},
+	}, WriteOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("WriteMetadataWithOptions failed: %v", err)
+	}
+	if !slices.Equal(result.Set, []string{"Keywords"}) {
+		t.Errorf("Expected Set [Keywords], got %v", result.Set)
+	}
+
+	metadata, err = et.ReadMetadataWithOptions(ctx, editPath, lists)
+	if err != nil {
+		t.Fatalf("ReadMetadataWithOptions failed: %v", err)
+	}
+	keywords, err = Metadata(metadata).Strings("Keywords")
+	if err != nil || !slices.Equal(keywords, []string{"sunset", "beach, sand", "holiday"}) {
+		t.Errorf("Keywords = %v, %v", keywords, err)
+	}
+}
+
+func TestWriteTypedValues(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+	taken := time.Date(2021, 3, 4, 5, 6, 7, 0, time.FixedZone("", 9*3600))
+
+	_, err = et.WriteMetadataWithOptions(context.Background(), srcPath, dstPath, map[string]any{
+		"DateTimeOriginal": taken,
+		"ExposureTime":     big.NewRat(1, 250),
+		"FNumber":          2.8,
+		"GPSLatitude":      -33.8568,
+		"GPSLongitude":     151.2153,
+		"Orientation":      Raw(6),
+	}, WriteOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("WriteMetadataWithOptions failed: %v", err)
+	}
+
+	metadata, err := et.ReadMetadataWithOptions(context.Background(), dstPath, ReadOptions{Numeric: true})
+	if err != nil {
+		t.Fatalf("ReadMetadataWithOptions failed: %v", err)
+	}
+	m := Metadata(metadata)
+
+	if got, err := m.Time("DateTimeOriginal"); err != nil || !got.Equal(taken) {
+		t.Errorf("DateTimeOriginal = %v, %v; want %v", got, err, taken)
+	}
+	if got, err := m.Float("ExposureTime"); err != nil || got != 0.004 {
+		t.Errorf("ExposureTime = %v, %v; want 0.004", got, err)
+	}
+	if got, err := m.Float("FNumber"); err != nil || got != 2.8 {
+		t.Errorf("FNumber = %v, %v; want 2.8", got, err)
+	}
+	if m["GPSLatitudeRef"] != "S" || m["GPSLongitudeRef"] != "E" {
+		t.Errorf("GPS refs = %v %v, want S E", m["GPSLatitudeRef"], m["GPSLongitudeRef"])
+	}
+	if got, err := m.Float("GPSLatitude"); err != nil || got < 33.8567 || got > 33.8569 {
+		t.Errorf("GPSLatitude = %v, %v; want 33.8568", got, err)
+	}
+	if got, err := m.Int("Orientation"); err != nil || got != 6 {
+		t.Errorf("Orientation = %v, %v; want 6", got, err)
+	}
+}
+
+func TestWriteModes(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx := context.Background()
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dstPath := filepath.Join(t.TempDir(), "output.jpg")
+
+	// Make exists and Copyright doesn't, so create-only only sets Copyright
+	_, err = et.WriteMetadataWithOptions(ctx, srcPath, dstPath, map[string]any{
+		"Make":      "Other",
+		"Copyright": "Example Corp",
+	}, WriteOptions{Mode: WriteModeCreateOnly})
+	if err != nil {
+		t.Fatalf("WriteMetadataWithOptions failed: %v", err)
+	}
+	metadata, err := et.ReadMetadata(dstPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	if metadata["Make"] != "Canon" || metadata["Copyright"] != "Example Corp" {
+		t.Errorf("create-only: Make = %v, Copyright = %v", metadata["Make"], metadata["Copyright"])
+	}
+
+	// Edit-only changes Make but doesn't create Artist
+	_, err = et.WriteMetadataWithOptions(ctx, srcPath, dstPath, map[string]any{
+		"Make":   "Other",
+		"Artist": "Nobody",
+	}, WriteOptions{Mode: WriteModeEditOnly})
+	if err != nil {
+		t.Fatalf("WriteMetadataWithOptions failed: %v", err)
+	}
+	metadata, err = et.ReadMetadata(dstPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	if _, ok := metadata["Artist"]; ok || metadata["Make"] != "Other" {
+		t.Errorf("edit-only: Make = %v, Artist = %v", metadata["Make"], metadata["Artist"])
+	}
+}
+
+func TestWriteReplaceValue(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx := context.Background()
+	srcPath := filepath.Join("testdata", "test.jpg")
+	dir := t.TempDir()
+
+	tests := []struct {
+		old  string
+		want string
+	}{
+		{"Canon", "Canon Inc."},
+		{"SAMSUNG ", "Canon"},
+	}
+	for _, tt := range tests {
+		dstPath := filepath.Join(dir, "output.jpg")
+		_, err := et.WriteMetadataWithOptions(ctx, srcPath, dstPath, map[string]any{
+			"Make": Replace(tt.old, "Canon Inc."),
+		}, WriteOptions{})
+		if err != nil {
+			t.Fatalf("WriteMetadataWithOptions failed: %v", err)
+		}
+		metadata, err := et.ReadMetadata(dstPath)
+		if err != nil {
+			t.Fatalf("ReadMetadata failed: %v", err)
+		}
+		if metadata["Make"] != tt.want {
+			t.Errorf("Replace(%q): Make = %v, want %q", tt.old, metadata["Make"], tt.want)
+		}
+	}
+}
+
+func TestWriteCondition(t *testing.T) {
+	et, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create ExifTool: %v", err)
+	}
+	defer et.Close()
+
+	ctx := context.Background()
+	srcPath := filepath.Join("testdata", "test.jpg")
+	tags := map[string]any{"Artist": "Conditional"}
+
+	dstPath := filepath.Join(t.TempDir(), "met.jpg")
+	_, err = et.WriteMetadataWithOptions(ctx, srcPath, dstPath, tags, WriteOptions{
+		Condition: `$Make eq "Canon" and not $Copyright and ${IFD0:Orientation#} == 1`,
+	})
+	if err != nil {
+		t.Fatalf("WriteMetadataWithOptions failed: %v", err)
+	}
+	metadata, err := et.ReadMetadata(dstPath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	if metadata["Artist"] != "Conditional" {
+		t.Errorf("Artist = %v, want Conditional", metadata["Artist"])
+	}
+
+	dstPath = filepath.Join(t.TempDir(), "unmet.jpg")
+	_, err = et.WriteMetadataWithOptions(ctx, srcPath, dstPath, tags, WriteOptions{
+		Condition: `$Make eq "SAMSUNG "`,
+	})
+	if !errors.Is(err, ErrConditionFailed) {
+		t.Fatalf("WriteMetadataWithOptions error = %v, want ErrConditionFailed", err)
+	}
+	if _, err := os.Stat(dstPath); !os.IsNotExist(err) {
+		t.Error("Nothing should be written if the condition is false")
+	}
+
+	var perlErr *PerlError
+	_, err = et.WriteMetadataWithOptions(ctx, srcPath, dstPath, tags, WriteOptions{Condition: `$Make eq`})
+	if !errors.As(err, &perlErr) {
+		t.Errorf("WriteMetadataWithOptions error = %v, want *PerlError", err)
+	}
+}
+
+func TestWriteInPlaceAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "photo.jpg")
+	if err := os.WriteFile(path, []byte("original"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	modTime := time.Date(2010, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set times: %v", err)
+	}
+
+	rewrite := func(r io.Reader, w io.Writer) (*WriteResult, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		_, err = w.Write(append(data, " rewritten"...))
+		return &WriteResult{Changed: true}, err
+	}
+	for range 2 {
+		_, err := writeFile(path, "", WriteOptions{PreserveModTime: true, Backup: true}, rewrite)
+		if err != nil {
+			t.Fatalf("writeFile failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "original rewritten rewritten" {
+		t.Errorf("File = %q, %v", data, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Mode = %v, want 0600", info.Mode().Perm())
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("ModTime = %v, want %v", info.ModTime(), modTime)
+	}
+
+	// The backup holds the file from before the first write
+	backup, err := os.ReadFile(path + "_original")
+	if err != nil || string(backup) != "original" {
+		t.Errorf("Backup = %q, %v; want the original file", backup, err)
+	}
+
+	// Only the file itself and its backup are left
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 files, got %d", len(entries))
+	}
+}
+
+func TestWriteInPlaceUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "photo.jpg")
+	if err := os.WriteFile(path, []byte("original"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	modTime := time.Date(2010, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set times: %v", err)
+	}
+
+	_, err := writeFile(path, "", WriteOptions{Backup: true}, func(r io.Reader, w io.Writer) (*WriteResult, error) {
+		_, err := io.Copy(w, r)
+		return &WriteResult{Changed: false}, err
+	})
+	if err != nil {
+		t.Fatalf("writeFile failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("ModTime = %v, want %v", info.ModTime(), modTime)
+	}
+	// Neither a backup nor the temporary file is left
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 file, got %d", len(entries))
+	}
+}
+
+func TestWriteFailureKeepsDestination(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "photo.jpg")
+	if err := os.WriteFile(path, []byte("original"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	_, err := writeFile(path, "", WriteOptions{}, func(r io.Reader, w io.Writer) (*WriteResult, error) {
+		w.Write([]byte("partial"))
+		return nil, errors.New("rewrite failed")
+	})
+	if err == nil {
+		t.Fatal("writeFile succeeded, want error")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "original" {
+		t.Errorf("Destination = %q, %v; want it untouched", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Temporary file should be removed, %d files left", len(entries))
+	}
+}